@@ -1,19 +1,17 @@
 package business
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/rysavyvladan/go-revolut/business/1.0/request"
+	"github.com/rysavyvladan/go-revolut/money"
 	"net/http"
 	"time"
 )
 
 type AccountService struct {
-	accessToken string
-	sandbox     bool
-
-	err error
+	options *request.Options
 }
 
 type AccountState string
@@ -29,9 +27,9 @@ type AccountResp struct {
 	// the account name
 	Name string `json:"name"`
 	// the available balance
-	Balance float64 `json:"balance"`
+	Balance money.Decimal `json:"balance"`
 	// the account currency
-	Currency string `json:"currency"`
+	Currency money.Currency `json:"currency"`
 	// the account state, one of active, inactive
 	State AccountState `json:"state"`
 	// determines if the account is visible to other businesses on Revolut
@@ -112,25 +110,22 @@ type BeneficiaryAddress struct {
 // List: This endpoint retrieves your accounts.
 // doc: https://revolut-engineering.github.io/api-docs/#business-api-business-api-accounts-get-accounts
 func (a *AccountService) List() ([]*AccountResp, error) {
-	if a.err != nil {
-		return nil, a.err
-	}
+	return a.ListWithContext(context.Background())
+}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodGet,
-		Url:         "https://b2b.revolut.com/api/1.0/accounts",
-		AccessToken: a.accessToken,
-		Sandbox:     a.sandbox,
-		Body:        nil,
+// ListWithContext: same as List, the request is bound to ctx for cancellation and deadline.
+func (a *AccountService) ListWithContext(ctx context.Context) ([]*AccountResp, error) {
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: a.options,
+		Method:  http.MethodGet,
+		Url:     "https://b2b.revolut.com/api/1.0/accounts",
+		Body:    nil,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
-
 	var r []*AccountResp
 	if err := json.Unmarshal(resp, &r); err != nil {
 		return nil, err
@@ -142,25 +137,22 @@ func (a *AccountService) List() ([]*AccountResp, error) {
 // WithId: This endpoint retrieves one of your accounts by ID.
 // doc: https://revolut-engineering.github.io/api-docs/#business-api-business-api-accounts-get-account
 func (a *AccountService) WithId(id string) (*AccountResp, error) {
-	if a.err != nil {
-		return nil, a.err
-	}
+	return a.WithIdWithContext(context.Background(), id)
+}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodGet,
-		Url:         fmt.Sprintf("https://b2b.revolut.com/api/1.0/accounts/%s", id),
-		AccessToken: a.accessToken,
-		Sandbox:     a.sandbox,
-		Body:        nil,
+// WithIdWithContext: same as WithId, the request is bound to ctx for cancellation and deadline.
+func (a *AccountService) WithIdWithContext(ctx context.Context, id string) (*AccountResp, error) {
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: a.options,
+		Method:  http.MethodGet,
+		Url:     fmt.Sprintf("https://b2b.revolut.com/api/1.0/accounts/%s", id),
+		Body:    nil,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
-
 	r := &AccountResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
 		return nil, err
@@ -172,24 +164,22 @@ func (a *AccountService) WithId(id string) (*AccountResp, error) {
 // DetailWithId: This endpoint retrieves individual account details.
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#accounts-get-account-details
 func (a *AccountService) DetailWithId(id string) ([]*AccountDetailResp, error) {
-	if a.err != nil {
-		return nil, a.err
-	}
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodGet,
-		Url:         fmt.Sprintf("https://b2b.revolut.com/api/1.0/accounts/%s/bank-details", id),
-		AccessToken: a.accessToken,
-		Sandbox:     a.sandbox,
-		Body:        nil,
+	return a.DetailWithIdWithContext(context.Background(), id)
+}
+
+// DetailWithIdWithContext: same as DetailWithId, the request is bound to ctx for cancellation and deadline.
+func (a *AccountService) DetailWithIdWithContext(ctx context.Context, id string) ([]*AccountDetailResp, error) {
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: a.options,
+		Method:  http.MethodGet,
+		Url:     fmt.Sprintf("https://b2b.revolut.com/api/1.0/accounts/%s/bank-details", id),
+		Body:    nil,
 	})
 	if err != nil {
 		return []*AccountDetailResp{}, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
-
 	r := []*AccountDetailResp{}
 	if err := json.Unmarshal(resp, &r); err != nil {
 		return nil, err
@@ -0,0 +1,205 @@
+package business
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+const defaultAssertionLifetime = 5 * time.Minute
+
+// SigningKey is a key the client assertion is signed with.
+type SigningKey struct {
+	// an optional key ID sent in the kid header of the assertion
+	Id string
+	// an RSA private key or an external signer holding one, e.g. backed by a KMS or an HSM
+	Signer crypto.Signer
+}
+
+// KeyRing holds the keys of the API certificates and the one currently used to sign client assertions.
+// Keys can be added and activated while the client is in use, e.g. when the certificate is rotated.
+type KeyRing struct {
+	mu     sync.RWMutex
+	keys   []SigningKey
+	active int
+}
+
+// NewKeyRing creates a key ring, the first key is active.
+func NewKeyRing(keys ...SigningKey) *KeyRing {
+	return &KeyRing{
+		keys: keys,
+	}
+}
+
+// Add adds key to the ring without activating it, a key with the same ID is replaced.
+func (k *KeyRing) Add(key SigningKey) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for i := range k.keys {
+		if k.keys[i].Id == key.Id {
+			k.keys[i] = key
+			return
+		}
+	}
+	k.keys = append(k.keys, key)
+}
+
+// Activate makes the key with id the one client assertions are signed with.
+func (k *KeyRing) Activate(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for i := range k.keys {
+		if k.keys[i].Id == id {
+			k.active = i
+			return nil
+		}
+	}
+
+	return fmt.Errorf("business: no signing key with id %q", id)
+}
+
+// Remove removes the key with id, the active key cannot be removed.
+func (k *KeyRing) Remove(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for i := range k.keys {
+		if k.keys[i].Id != id {
+			continue
+		}
+		if i == k.active {
+			return fmt.Errorf("business: signing key %q is active", id)
+		}
+		k.keys = append(k.keys[:i], k.keys[i+1:]...)
+		if i < k.active {
+			k.active--
+		}
+		return nil
+	}
+
+	return fmt.Errorf("business: no signing key with id %q", id)
+}
+
+// Active returns the key client assertions are signed with.
+func (k *KeyRing) Active() (SigningKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if len(k.keys) == 0 {
+		return SigningKey{}, errors.New("business: no signing key")
+	}
+
+	return k.keys[k.active], nil
+}
+
+// ParsePrivateKey parses an RSA private key in PEM or DER encoding, both PKCS#1 and PKCS#8 are supported.
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(data); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(data)
+	if err != nil {
+		return nil, errors.New("business: the private key is neither a PKCS#1 nor a PKCS#8 key")
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("business: the private key is %T, an RSA key is required", key)
+	}
+
+	return rsaKey, nil
+}
+
+// LoadPrivateKeyFile reads an RSA private key from a PEM or DER file.
+func LoadPrivateKeyFile(path string) (*rsa.PrivateKey, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePrivateKey(b)
+}
+
+type assertionHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid,omitempty"`
+}
+
+type assertionClaims struct {
+	Iss string `json:"iss"`
+	Sub string `json:"sub"`
+	Aud string `json:"aud"`
+	Iat int64  `json:"iat"`
+	Exp int64  `json:"exp"`
+	Jti string `json:"jti"`
+}
+
+// generateClientAssertion creates a JWT signed with RS256 by the active key.
+// doc: https://revolut-engineering.github.io/api-docs/business-api/#oauth-exchange-authorisation-code
+func (oa *OAuthService) generateClientAssertion() (string, error) {
+	if oa.keys == nil {
+		return "", errors.New("business: no signing key")
+	}
+	key, err := oa.keys.Active()
+	if err != nil {
+		return "", err
+	}
+	if _, ok := key.Signer.Public().(*rsa.PublicKey); !ok {
+		return "", fmt.Errorf("business: signing key %q is not an RSA key", key.Id)
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	header, err := json.Marshal(assertionHeader{
+		Alg: "RS256",
+		Typ: "JWT",
+		Kid: key.Id,
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(assertionClaims{
+		Iss: oa.issuer,
+		Sub: oa.clientId,
+		Aud: aud,
+		Iat: now.Unix(),
+		Exp: now.Add(oa.assertionLifetime).Unix(),
+		Jti: hex.EncodeToString(jti),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+
+	signature, err := key.Signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,144 @@
+package business
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var (
+	bicPattern     = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+	countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	digitsPattern  = regexp.MustCompile(`^[0-9]+$`)
+	ibanPattern    = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+	ifscPattern    = regexp.MustCompile(`^[A-Z]{4}0[A-Z0-9]{6}$`)
+)
+
+// the countries of the SEPA scheme
+var sepaCountries = map[string]bool{
+	"AD": true, "AT": true, "BE": true, "BG": true, "CH": true, "CY": true, "CZ": true, "DE": true, "DK": true,
+	"EE": true, "ES": true, "FI": true, "FR": true, "GB": true, "GI": true, "GR": true, "HR": true, "HU": true,
+	"IE": true, "IS": true, "IT": true, "LI": true, "LT": true, "LU": true, "LV": true, "MC": true, "MT": true,
+	"NL": true, "NO": true, "PL": true, "PT": true, "RO": true, "SE": true, "SI": true, "SK": true, "SM": true,
+	"VA": true,
+}
+
+// compact removes the spaces and dashes people put into bank details, e.g. 12-34-56 or GB29 NWBK 6016.
+func compact(s string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(s))
+}
+
+// checkIBAN verifies the format and the mod-97 checksum of an IBAN.
+func checkIBAN(iban string) error {
+	iban = compact(iban)
+	if !ibanPattern.MatchString(iban) {
+		return fmt.Errorf("%q is not an IBAN", iban)
+	}
+
+	// the country and the check digits go to the end, letters become numbers A=10 ... Z=35
+	var b strings.Builder
+	for _, c := range iban[4:] + iban[:4] {
+		if c >= 'A' && c <= 'Z' {
+			fmt.Fprintf(&b, "%d", c-'A'+10)
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	n, _ := new(big.Int).SetString(b.String(), 10)
+	if new(big.Int).Mod(n, big.NewInt(97)).Int64() != 1 {
+		return fmt.Errorf("%q has an invalid checksum", iban)
+	}
+
+	return nil
+}
+
+// checkBIC verifies the format of a BIC (SWIFT code) of 8 or 11 characters.
+func checkBIC(bic string) error {
+	if !bicPattern.MatchString(compact(bic)) {
+		return fmt.Errorf("%q is not a BIC of 8 or 11 characters", bic)
+	}
+
+	return nil
+}
+
+// checkSortCode verifies a UK sort code of 6 digits, dashes are allowed.
+func checkSortCode(sortCode string) error {
+	if s := compact(sortCode); len(s) != 6 || !digitsPattern.MatchString(s) {
+		return fmt.Errorf("%q is not a sort code of 6 digits", sortCode)
+	}
+
+	return nil
+}
+
+// checkUKAccountNo verifies a UK account number of 8 digits.
+func checkUKAccountNo(accountNo string) error {
+	if s := compact(accountNo); len(s) != 8 || !digitsPattern.MatchString(s) {
+		return fmt.Errorf("%q is not a UK account number of 8 digits", accountNo)
+	}
+
+	return nil
+}
+
+// checkRoutingNumber verifies the length and the checksum of a US ABA routing number.
+func checkRoutingNumber(routingNumber string) error {
+	s := compact(routingNumber)
+	if len(s) != 9 || !digitsPattern.MatchString(s) {
+		return fmt.Errorf("%q is not a routing number of 9 digits", routingNumber)
+	}
+
+	sum := 0
+	for i, weight := range []int{3, 7, 1, 3, 7, 1, 3, 7, 1} {
+		sum += int(s[i]-'0') * weight
+	}
+	if sum%10 != 0 {
+		return fmt.Errorf("%q has an invalid checksum", routingNumber)
+	}
+
+	return nil
+}
+
+// checkCLABE verifies the length and the control digit of a Mexican CLABE of 18 digits.
+func checkCLABE(clabe string) error {
+	s := compact(clabe)
+	if len(s) != 18 || !digitsPattern.MatchString(s) {
+		return fmt.Errorf("%q is not a CLABE of 18 digits", clabe)
+	}
+
+	sum := 0
+	for i := 0; i < 17; i++ {
+		sum += int(s[i]-'0') * []int{3, 7, 1}[i%3] % 10
+	}
+	if (10-sum%10)%10 != int(s[17]-'0') {
+		return fmt.Errorf("%q has an invalid control digit", clabe)
+	}
+
+	return nil
+}
+
+// checkIFSC verifies the format of an Indian IFSC, 4 letters, a zero and 6 letters or digits.
+func checkIFSC(ifsc string) error {
+	if !ifscPattern.MatchString(compact(ifsc)) {
+		return fmt.Errorf("%q is not an IFSC of 11 characters", ifsc)
+	}
+
+	return nil
+}
+
+// checkBSB verifies an Australian BSB code of 6 digits, dashes are allowed.
+func checkBSB(bsb string) error {
+	if s := compact(bsb); len(s) != 6 || !digitsPattern.MatchString(s) {
+		return fmt.Errorf("%q is not a BSB code of 6 digits", bsb)
+	}
+
+	return nil
+}
+
+// checkCountry verifies an ISO 3166 alpha-2 country code.
+func checkCountry(country string) error {
+	if !countryPattern.MatchString(country) {
+		return fmt.Errorf("%q is not an ISO 3166 alpha-2 country code", country)
+	}
+
+	return nil
+}
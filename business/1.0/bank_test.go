@@ -0,0 +1,115 @@
+package business
+
+import "testing"
+
+func TestBankChecks(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   func(string) error
+		valid   []string
+		invalid []string
+	}{
+		{
+			"IBAN", checkIBAN,
+			[]string{"GB29NWBK60161331926819", "GB82 WEST 1234 5698 7654 32", "DE89370400440532013000", "FR1420041010050500013M02606", "NL91ABNA0417164300", "de89-3704-0044-0532-0130-00"},
+			[]string{"", "GB29NWBK60161331926818", "DE88370400440532013000", "NL91ABNA0417164301", "GB29", "GB29NWBK6016133192681912345678901234", "1129NWBK60161331926819", "GB2XNWBK60161331926819", "GB29NWBK6016133192681!"},
+		},
+		{
+			"BIC", checkBIC,
+			[]string{"NWBKGB2L", "COBADEFFXXX", "DEUTDEFF500", "cobadeffxxx"},
+			[]string{"", "NWBKGB2", "COBADEFF1", "COBADEFFXX", "1WBKGB2L", "NWBK1B2L", "NWBKGB2L0000"},
+		},
+		{
+			"sort code", checkSortCode,
+			[]string{"601613", "60-16-13", "60 16 13"},
+			[]string{"", "60161", "6016133", "60-16-1A"},
+		},
+		{
+			"UK account number", checkUKAccountNo,
+			[]string{"31926819", "3192 6819"},
+			[]string{"", "3192681", "319268190", "3192681A"},
+		},
+		{
+			"routing number", checkRoutingNumber,
+			[]string{"021000021", "011000015", "122105155"},
+			[]string{"", "021000022", "122105156", "02100002", "0210000210", "02100002A"},
+		},
+		{
+			"CLABE", checkCLABE,
+			[]string{"032180000118359719", "002010077777777771", "0321 8000 0118 3597 19"},
+			[]string{"", "032180000118359710", "002010077777777772", "03218000011835971", "0321800001183597190", "03218000011835971A"},
+		},
+		{
+			"IFSC", checkIFSC,
+			[]string{"SBIN0001234", "HDFC0000001", "icic0abc123"},
+			[]string{"", "SBIN1001234", "SBIN000123", "SBIN00012345", "1BIN0001234"},
+		},
+		{
+			"BSB", checkBSB,
+			[]string{"062000", "062-000"},
+			[]string{"", "06200", "0620001", "062-00A"},
+		},
+		{
+			"country", checkCountry,
+			[]string{"GB", "US"},
+			[]string{"", "gb", "GBR", "G1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, v := range tt.valid {
+				if err := tt.check(v); err != nil {
+					t.Errorf("%q: %v", v, err)
+				}
+			}
+			for _, v := range tt.invalid {
+				if tt.check(v) == nil {
+					t.Errorf("%q: got no error", v)
+				}
+			}
+		})
+	}
+}
+
+func TestNonRevolutCounterpartyReqValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     NonRevolutCounterpartyReq
+		invalid []string
+	}{
+		{"UK", NonRevolutCounterpartyReq{CompanyName: "Acme Ltd", BankCountry: "GB", Currency: "GBP", AccountNo: "31926819", SortCode: "60-16-13"}, nil},
+		{"US", NonRevolutCounterpartyReq{CompanyName: "Acme Inc", BankCountry: "US", Currency: "USD", AccountNo: "123456789", RoutingNumber: "021000021"}, nil},
+		{"SEPA", NonRevolutCounterpartyReq{IndividualName: &NonRevolutCounterpartyReqIndividualName{FirstName: "Hans", LastName: "Muller"}, BankCountry: "DE", Currency: "EUR", Iban: "DE89370400440532013000", Bic: "COBADEFFXXX"}, nil},
+		{"MX", NonRevolutCounterpartyReq{CompanyName: "Acme SA", BankCountry: "MX", Currency: "MXN", Clabe: "032180000118359719"}, nil},
+		{"IN", NonRevolutCounterpartyReq{CompanyName: "Acme Pvt", BankCountry: "IN", Currency: "INR", AccountNo: "1234567890", Ifsc: "SBIN0001234"}, nil},
+		{"AU", NonRevolutCounterpartyReq{CompanyName: "Acme Pty", BankCountry: "AU", Currency: "AUD", AccountNo: "12345678", BsbCode: "062-000"}, nil},
+		{"SWIFT", NonRevolutCounterpartyReq{CompanyName: "Acme KK", BankCountry: "JP", Currency: "JPY", AccountNo: "1234567", Bic: "MHCBJPJT"}, nil},
+		{"UK without a sort code", NonRevolutCounterpartyReq{CompanyName: "Acme Ltd", BankCountry: "GB", Currency: "GBP", AccountNo: "31926819"}, []string{"NonRevolutCounterpartyReq.SortCode"}},
+		{"US with a bad checksum", NonRevolutCounterpartyReq{CompanyName: "Acme Inc", BankCountry: "US", Currency: "USD", AccountNo: "123456789", RoutingNumber: "021000022"}, []string{"NonRevolutCounterpartyReq.RoutingNumber"}},
+		{"SEPA with a bad IBAN and no BIC", NonRevolutCounterpartyReq{CompanyName: "Acme GmbH", BankCountry: "DE", Currency: "EUR", Iban: "DE88370400440532013000"}, []string{"NonRevolutCounterpartyReq.Iban", "NonRevolutCounterpartyReq.Bic"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if len(tt.invalid) == 0 {
+				if err != nil {
+					t.Errorf("got %v", err)
+				}
+				return
+			}
+			errs, ok := err.(ValidationErrors)
+			if !ok {
+				t.Fatalf("got %v, want ValidationErrors", err)
+			}
+			fields := map[string]bool{}
+			for _, e := range errs {
+				fields[e.Field] = true
+			}
+			for _, f := range tt.invalid {
+				if !fields[f] {
+					t.Errorf("got %v, want an error of %s", err, f)
+				}
+			}
+		})
+	}
+}
@@ -1,117 +1,90 @@
 package business
 
 import (
+	"context"
 	"crypto/rsa"
-	"time"
+	"github.com/rysavyvladan/go-revolut/business/1.0/request"
+	"github.com/rysavyvladan/go-revolut/environment"
 )
 
 type Client struct {
-	clientId     string
-	sandbox      bool
-	privateKey   *rsa.PrivateKey
-	issuer       string
-	refreshToken string
-
-	accessToken           string
-	accessTokenExpiration int64
-	oa                    *OAuthService
+	clientId    string
+	environment environment.Environment
+	privateKey  *rsa.PrivateKey
+	issuer      string
+
+	oa      *OAuthService
+	tokens  *tokenSource
+	options *request.Options
 }
 
-func NewClient(clientId, refreshToken string, privateKey *rsa.PrivateKey, issuer string, sandbox bool) (*Client, error) {
-	oa := &OAuthService{
-		clientId:   clientId,
-		privateKey: privateKey,
-		issuer:     issuer,
-		sandbox:    sandbox}
+// NewClient creates a client of the Business API and requests the first access token unless a valid one
+// is found in the TokenStore (see WithTokenStore). The access token is refreshed automatically before it expires.
+// privateKey may be nil when the key is provided by WithSigner or WithKeyRing.
+// The sandbox environment is used when sandbox is set, WithEnvironment takes precedence.
+func NewClient(clientId, refreshToken string, privateKey *rsa.PrivateKey, issuer string, sandbox bool, opts ...Option) (*Client, error) {
+	o := newOptions(opts)
+	oa := newOAuth(clientId, privateKey, issuer, sandbox, o)
 
-	accessTokenExpiration := time.Now().Unix()
-	accessToken, err := oa.RefreshAccessToken(refreshToken)
-	if err != nil {
+	tokens := newTokenSource(oa, refreshToken, o.refreshMargin, o.tokenStore)
+	if _, err := tokens.Token(context.Background()); err != nil {
 		return nil, err
 	}
-	accessTokenExpiration += int64(accessToken.ExpiresIn)
 
-	return &Client{
-		clientId:     clientId,
-		sandbox:      sandbox,
-		privateKey:   privateKey,
-		issuer:       issuer,
-		refreshToken: refreshToken,
+	// the services share the transport of the OAuthService, only they are authorised by the token source
+	ro := *oa.options
+	ro.TokenSource = tokens
 
-		accessToken:           accessToken.AccessToken,
-		accessTokenExpiration: accessTokenExpiration,
-		oa:                    oa,
+	return &Client{
+		clientId:    clientId,
+		environment: oa.environment,
+		privateKey:  privateKey,
+		issuer:      issuer,
+
+		oa:      oa,
+		tokens:  tokens,
+		options: &ro,
 	}, nil
 }
 
 func (b *Client) Account() *AccountService {
 	return &AccountService{
-		accessToken: b.accessToken,
-		sandbox:     b.sandbox,
-		err:         b.refreshAccessToken(),
+		options: b.options,
 	}
 }
 
 func (b *Client) Counterparty() *CounterpartyService {
 	return &CounterpartyService{
-		accessToken: b.accessToken,
-		sandbox:     b.sandbox,
-		err:         b.refreshAccessToken(),
+		options: b.options,
 	}
 }
 
 func (b *Client) Transfer() *TransferService {
 	return &TransferService{
-		accessToken: b.accessToken,
-		sandbox:     b.sandbox,
-		err:         b.refreshAccessToken(),
+		options: b.options,
 	}
 }
 
 func (b *Client) Payment() *PaymentService {
 	return &PaymentService{
-		accessToken: b.accessToken,
-		sandbox:     b.sandbox,
-		err:         b.refreshAccessToken(),
+		options: b.options,
 	}
 }
 
 func (b *Client) PaymentDraft() *PaymentDraftService {
 	return &PaymentDraftService{
-		accessToken: b.accessToken,
-		sandbox:     b.sandbox,
-		err:         b.refreshAccessToken(),
+		options: b.options,
 	}
 }
 
 func (b *Client) Exchange() *ExchangeService {
 	return &ExchangeService{
-		accessToken: b.accessToken,
-		sandbox:     b.sandbox,
-		err:         b.refreshAccessToken(),
+		options: b.options,
 	}
 }
 
 func (b *Client) Webhook() *WebhookService {
 	return &WebhookService{
-		accessToken: b.accessToken,
-		sandbox:     b.sandbox,
-		err:         b.refreshAccessToken(),
+		options: b.options,
 	}
 }
-
-func (b *Client) refreshAccessToken() error {
-	if b.accessTokenExpiration > time.Now().Unix() {
-		return nil
-	}
-
-	expirationOfAccessToken := time.Now().Unix()
-	accessToken, err := b.oa.RefreshAccessToken(b.refreshToken)
-	if err != nil {
-		return err
-	}
-	b.accessTokenExpiration = expirationOfAccessToken + int64(accessToken.ExpiresIn)
-	b.accessToken = accessToken.AccessToken
-
-	return nil
-}
@@ -0,0 +1,142 @@
+package business
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// the host of the consent page in production, see environment.Environment.Url
+const consentHost = "business.revolut.com"
+
+// ConsentFlow describes how the authorisation code is captured by OAuthService.Authorise.
+type ConsentFlow struct {
+	// the redirect URI set up for the API certificate, e.g. http://127.0.0.1:8080/callback;
+	// a listener is started on its host and port and captures requests of its path
+	RedirectUri string
+	// an optional address the listener binds to instead of the host and port of RedirectUri,
+	// e.g. when the redirect URI points to a tunnel or a reverse proxy
+	ListenAddr string
+	// an optional state parameter, a random one is generated when empty
+	State string
+	// called with the URL of the consent page once the listener is ready, e.g. to print it or open a browser
+	OnAuthorisationURL func(authorisationUrl string)
+}
+
+// AuthorisationURL returns the address of the consent page. After the user authorises the app
+// Revolut redirects to redirectUri with the code and state query parameters.
+// doc: https://revolut-engineering.github.io/api-docs/business-api/#oauth-get-authorisation-code
+func (oa *OAuthService) AuthorisationURL(redirectUri, state string) string {
+	params := url.Values{}
+	params.Add("client_id", oa.clientId)
+	params.Add("redirect_uri", redirectUri)
+	params.Add("response_type", "code")
+	if state != "" {
+		params.Add("state", state)
+	}
+
+	return fmt.Sprintf("%s/app-confirm?%s", oa.environment.Url(consentHost), params.Encode())
+}
+
+// Authorise runs the consent flow: it starts a listener on the redirect URI, hands the URL of the consent page
+// to flow.OnAuthorisationURL, waits for the redirect, validates the state and exchanges the code for the tokens.
+// It returns when the tokens are obtained, the user denies the access or ctx is done.
+func (oa *OAuthService) Authorise(ctx context.Context, flow ConsentFlow) (*OAuthResp, error) {
+	redirectUri, err := url.Parse(flow.RedirectUri)
+	if err != nil {
+		return nil, err
+	}
+	if redirectUri.Host == "" {
+		return nil, fmt.Errorf("business: redirect URI %q has no host", flow.RedirectUri)
+	}
+
+	state := flow.State
+	if state == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		state = hex.EncodeToString(b)
+	}
+
+	addr := flow.ListenAddr
+	if addr == "" {
+		addr = redirectUri.Host
+		if redirectUri.Port() == "" {
+			port := "80"
+			if redirectUri.Scheme == "https" {
+				port = "443"
+			}
+			addr = net.JoinHostPort(redirectUri.Hostname(), port)
+		}
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	path := redirectUri.Path
+	if path == "" {
+		path = "/"
+	}
+
+	codes := make(chan string, 1)
+	errs := make(chan error, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		switch {
+		case r.URL.Path != path:
+			http.NotFound(w, r)
+
+		case q.Get("code") == "" && q.Get("error") == "":
+			http.Error(w, "Missing code parameter.", http.StatusBadRequest)
+
+		case q.Get("state") != state:
+			http.Error(w, "Invalid state parameter.", http.StatusBadRequest)
+			select {
+			case errs <- errors.New("business: consent redirect with invalid state parameter"):
+			default:
+			}
+
+		case q.Get("error") != "":
+			http.Error(w, "Authorisation was not granted, you can close this window.", http.StatusForbidden)
+			select {
+			case errs <- fmt.Errorf("business: authorisation was not granted: %s", q.Get("error")):
+			default:
+			}
+
+		default:
+			fmt.Fprintln(w, "Authorisation granted, you can close this window.")
+			select {
+			case codes <- q.Get("code"):
+			default:
+			}
+		}
+	})
+
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	if flow.OnAuthorisationURL != nil {
+		flow.OnAuthorisationURL(oa.AuthorisationURL(flow.RedirectUri, state))
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case err := <-errs:
+		return nil, err
+	case code := <-codes:
+		return oa.ExchangeAuthorisationCodeWithContext(ctx, code)
+	}
+}
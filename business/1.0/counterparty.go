@@ -1,19 +1,17 @@
 package business
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/rysavyvladan/go-revolut/business/1.0/request"
+	"github.com/rysavyvladan/go-revolut/money"
 	"net/http"
 	"time"
 )
 
 type CounterpartyService struct {
-	accessToken string
-	sandbox     bool
-
-	err error
+	options *request.Options
 }
 
 type CounterpartyProfileType string
@@ -44,18 +42,29 @@ type RevolutCounterpartyReq struct {
 
 type NonRevolutCounterpartyReq struct {
 	// an optional name of the external company counterparty, this field must exist when individual_name does not
-	CompanyName  string                                `json:"company_name,omitempty"`
-	InvidualName NonRevolutCounterpartyReqInvidualName `json:"invidual_name,omitempty"`
+	CompanyName string `json:"company_name,omitempty"`
+	// an optional name of the external individual counterparty, this field must exist when company_name does not
+	IndividualName *NonRevolutCounterpartyReqIndividualName `json:"individual_name,omitempty"`
 	// the country of the bank
 	BankCountry string `json:"bank_country"`
 	// the currency of a counterparty's account
-	Currency string `json:"currency"`
+	Currency money.Currency `json:"currency"`
 	// bank account number
-	AccountNo string `json:"account_no"`
-	// sort code
-	SortCode string `json:"sort_code"`
-	// routing transit number
-	RoutingNumber string `json:"routing_number"`
+	AccountNo string `json:"account_no,omitempty"`
+	// sort code, UK accounts
+	SortCode string `json:"sort_code,omitempty"`
+	// routing transit number, US accounts
+	RoutingNumber string `json:"routing_number,omitempty"`
+	// IBAN, SEPA and international accounts
+	Iban string `json:"iban,omitempty"`
+	// BIC, SEPA and international accounts
+	Bic string `json:"bic,omitempty"`
+	// CLABE, Mexican accounts
+	Clabe string `json:"clabe,omitempty"`
+	// IFSC, Indian accounts
+	Ifsc string `json:"ifsc,omitempty"`
+	// BSB code, Australian accounts
+	BsbCode string `json:"bsb_code,omitempty"`
 	// an optional email address of the beneficiary
 	Email string `json:"email,omitempty"`
 	// an optional phone number of the beneficiary
@@ -63,10 +72,10 @@ type NonRevolutCounterpartyReq struct {
 	Address NonRevolutCounterpartyReqAddress `json:"address"`
 }
 
-type NonRevolutCounterpartyReqInvidualName struct {
-	// an optional first name of the external individual counterparty, this field must exist when company_name does not
+type NonRevolutCounterpartyReqIndividualName struct {
+	// the first name of the external individual counterparty
 	FirstName string `json:"first_name,omitempty"`
-	// an optional last name of the external individual counterparty, this field must exist when company_name does not
+	// the last name of the external individual counterparty
 	LastName string `json:"last_name,omitempty"`
 }
 
@@ -85,6 +94,110 @@ type NonRevolutCounterpartyReqAddress struct {
 	Country string `json:"country,omitempty"`
 }
 
+// Validate checks the bank details of n before they are sent: the company or individual name,
+// the bank country, the currency, the format and the checksum of each account identifier
+// and the identifiers required by the payment scheme of the currency and the bank country:
+//
+//	GBP in GB             sort code and account number
+//	USD in US             routing number and account number
+//	EUR in a SEPA country IBAN and BIC
+//	MXN in MX             CLABE
+//	INR in IN             IFSC and account number
+//	AUD in AU             BSB code and account number
+//	otherwise             BIC and IBAN or account number
+//
+// Spaces and dashes in the account identifiers are ignored, e.g. in the sort code 22-33-44.
+// All invalid fields are returned as ValidationErrors.
+func (n *NonRevolutCounterpartyReq) Validate() error {
+	var errs ValidationErrors
+	invalid := func(field, format string, a ...interface{}) {
+		errs = append(errs, &ValidationError{Field: "NonRevolutCounterpartyReq." + field, Message: fmt.Sprintf(format, a...)})
+	}
+	check := func(field, value string, fn func(string) error) {
+		if value == "" {
+			return
+		}
+		if err := fn(value); err != nil {
+			invalid(field, "%s", err)
+		}
+	}
+	require := func(field, value, name string) {
+		if value == "" {
+			invalid(field, "%s is required for %s accounts in %s", name, n.Currency, n.BankCountry)
+		}
+	}
+
+	var first, last string
+	if n.IndividualName != nil {
+		first, last = n.IndividualName.FirstName, n.IndividualName.LastName
+	}
+	individual := first != "" || last != ""
+	switch {
+	case n.CompanyName != "" && individual:
+		invalid("CompanyName", "provide either a company name or an individual name, not both")
+	case n.CompanyName == "" && !individual:
+		invalid("CompanyName", "a company name or an individual name is required")
+	case individual && first == "":
+		invalid("IndividualName.FirstName", "the first name of an individual is required")
+	case individual && last == "":
+		invalid("IndividualName.LastName", "the last name of an individual is required")
+	}
+
+	if err := checkCountry(n.BankCountry); err != nil {
+		invalid("BankCountry", "%s", err)
+	}
+	if err := validateCurrency("NonRevolutCounterpartyReq.Currency", n.Currency); err != nil {
+		errs = append(errs, err.(*ValidationError))
+	}
+
+	check("SortCode", n.SortCode, checkSortCode)
+	check("RoutingNumber", n.RoutingNumber, checkRoutingNumber)
+	check("Iban", n.Iban, checkIBAN)
+	check("Bic", n.Bic, checkBIC)
+	check("Clabe", n.Clabe, checkCLABE)
+	check("Ifsc", n.Ifsc, checkIFSC)
+	check("BsbCode", n.BsbCode, checkBSB)
+
+	switch {
+	case n.Currency == "GBP" && n.BankCountry == "GB":
+		require("SortCode", n.SortCode, "a sort code")
+		require("AccountNo", n.AccountNo, "an account number")
+		check("AccountNo", n.AccountNo, checkUKAccountNo)
+	case n.Currency == "USD" && n.BankCountry == "US":
+		require("RoutingNumber", n.RoutingNumber, "a routing number")
+		require("AccountNo", n.AccountNo, "an account number")
+	case n.Currency == "EUR" && sepaCountries[n.BankCountry]:
+		require("Iban", n.Iban, "an IBAN")
+		require("Bic", n.Bic, "a BIC")
+	case n.Currency == "MXN" && n.BankCountry == "MX":
+		require("Clabe", n.Clabe, "a CLABE")
+	case n.Currency == "INR" && n.BankCountry == "IN":
+		require("Ifsc", n.Ifsc, "an IFSC")
+		require("AccountNo", n.AccountNo, "an account number")
+	case n.Currency == "AUD" && n.BankCountry == "AU":
+		require("BsbCode", n.BsbCode, "a BSB code")
+		require("AccountNo", n.AccountNo, "an account number")
+	default:
+		require("Bic", n.Bic, "a BIC")
+		if n.Iban == "" && n.AccountNo == "" {
+			invalid("Iban", "an IBAN or an account number is required for %s accounts in %s", n.Currency, n.BankCountry)
+		}
+	}
+
+	return errs.err()
+}
+
+// compacted returns a copy of n with the spaces and dashes removed from the account identifiers, the form
+// they were validated in, e.g. the sort code 22-33-44 is sent as 223344.
+func (n *NonRevolutCounterpartyReq) compacted() *NonRevolutCounterpartyReq {
+	c := *n
+	for _, f := range []*string{&c.AccountNo, &c.SortCode, &c.RoutingNumber, &c.Iban, &c.Bic, &c.Clabe, &c.Ifsc, &c.BsbCode} {
+		*f = compact(*f)
+	}
+
+	return &c
+}
+
 type CounterpartyState string
 
 const (
@@ -124,7 +237,7 @@ type CounterpartyRespAccount struct {
 	// the ID of a counterparty's account
 	Id string `json:"id"`
 	// the currency of a counterparty's account
-	Currency string `json:"currency"`
+	Currency money.Currency `json:"currency"`
 	// the type of account, revolut or external
 	Type string `json:"type"`
 	// bank account number
@@ -140,6 +253,12 @@ type CounterpartyRespAccount struct {
 	RoutingNumber string `json:"routing_number"`
 	// BIC
 	Bic string `json:"bic"`
+	// CLABE
+	Clabe string `json:"clabe"`
+	// IFSC
+	Ifsc string `json:"ifsc"`
+	// BSB code
+	BsbCode string `json:"bsb_code"`
 	// indicates the possibility of the recipient charges: no or expected
 	RecipientCharges CounterpartyRecipientCharges `json:"recipient_charges"`
 }
@@ -147,24 +266,22 @@ type CounterpartyRespAccount struct {
 // AddRevolut: You can create a counterparty for an existing Revolut user.
 // doc: https://revolut-engineering.github.io/api-docs/#business-api-business-api-counterparties-add-revolut-counterparty
 func (c *CounterpartyService) AddRevolut(revolutCounterparty *RevolutCounterpartyReq) (*CounterpartyResp, error) {
-	if c.err != nil {
-		return nil, c.err
-	}
+	return c.AddRevolutWithContext(context.Background(), revolutCounterparty)
+}
 
-	resp, statusCode, err := request.New(request.Config{
+// AddRevolutWithContext: same as AddRevolut, the request is bound to ctx for cancellation and deadline.
+func (c *CounterpartyService) AddRevolutWithContext(ctx context.Context, revolutCounterparty *RevolutCounterpartyReq) (*CounterpartyResp, error) {
+	resp, _, err := request.New(request.Config{
+		Context:     ctx,
+		Options:     c.options,
 		Method:      http.MethodPost,
 		Url:         "https://b2b.revolut.com/api/1.0/counterparty",
-		AccessToken: c.accessToken,
-		Sandbox:     c.sandbox,
 		Body:        revolutCounterparty,
 		ContentType: request.ContentType_APPLICATION_JSON,
 	})
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
 
 	r := &CounterpartyResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
@@ -175,28 +292,31 @@ func (c *CounterpartyService) AddRevolut(revolutCounterparty *RevolutCounterpart
 }
 
 // AddNonRevolut: You can create a counterparty for an non-Revolut bank account.
+// The bank details are checked by NonRevolutCounterpartyReq.Validate and the account identifiers are sent
+// without spaces and dashes, nonRevolutCounterparty is not modified.
 // doc: https://revolut-engineering.github.io/api-docs/#business-api-business-api-counterparties-add-non-revolut-counterparty
 func (c *CounterpartyService) AddNonRevolut(nonRevolutCounterparty *NonRevolutCounterpartyReq) (*CounterpartyResp, error) {
-	if c.err != nil {
-		return nil, c.err
+	return c.AddNonRevolutWithContext(context.Background(), nonRevolutCounterparty)
+}
+
+// AddNonRevolutWithContext: same as AddNonRevolut, the request is bound to ctx for cancellation and deadline.
+func (c *CounterpartyService) AddNonRevolutWithContext(ctx context.Context, nonRevolutCounterparty *NonRevolutCounterpartyReq) (*CounterpartyResp, error) {
+	if err := nonRevolutCounterparty.Validate(); err != nil {
+		return nil, err
 	}
 
-	resp, statusCode, err := request.New(request.Config{
+	resp, _, err := request.New(request.Config{
+		Context:     ctx,
+		Options:     c.options,
 		Method:      http.MethodPost,
 		Url:         "https://b2b.revolut.com/api/1.0/counterparty",
-		AccessToken: c.accessToken,
-		Sandbox:     c.sandbox,
 		ContentType: request.ContentType_APPLICATION_JSON,
-		Body:        nonRevolutCounterparty,
+		Body:        nonRevolutCounterparty.compacted(),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
-
 	r := &CounterpartyResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
 		return nil, err
@@ -209,22 +329,18 @@ func (c *CounterpartyService) AddNonRevolut(nonRevolutCounterparty *NonRevolutCo
 // Once a counterparty is deleted no payments can be made to it.
 // doc: https://revolut-engineering.github.io/api-docs/#business-api-business-api-counterparties-delete-counterparty
 func (c *CounterpartyService) Delete(id string) error {
-	if c.err != nil {
-		return c.err
-	}
+	return c.DeleteWithContext(context.Background(), id)
+}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodDelete,
-		Url:         fmt.Sprintf("https://b2b.revolut.com/api/1.0/counterparty/%s", id),
-		AccessToken: c.accessToken,
-		Sandbox:     c.sandbox,
-		Body:        nil,
+// DeleteWithContext: same as Delete, the request is bound to ctx for cancellation and deadline.
+func (c *CounterpartyService) DeleteWithContext(ctx context.Context, id string) error {
+	_, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: c.options,
+		Method:  http.MethodDelete,
+		Url:     fmt.Sprintf("https://b2b.revolut.com/api/1.0/counterparty/%s", id),
+		Body:    nil,
 	})
-
-	if statusCode != http.StatusNoContent {
-		return errors.New(string(resp))
-	}
-
 	if err != nil {
 		return err
 	}
@@ -235,25 +351,22 @@ func (c *CounterpartyService) Delete(id string) error {
 // WithId: This endpoint retrieves a counterparty by ID.
 // doc https://revolut-engineering.github.io/api-docs/#business-api-business-api-counterparties-get-counterparty
 func (c *CounterpartyService) WithId(id string) (*CounterpartyResp, error) {
-	if c.err != nil {
-		return nil, c.err
-	}
+	return c.WithIdWithContext(context.Background(), id)
+}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodGet,
-		Url:         fmt.Sprintf("https://b2b.revolut.com/api/1.0/counterparty/%s", id),
-		AccessToken: c.accessToken,
-		Sandbox:     c.sandbox,
-		Body:        nil,
+// WithIdWithContext: same as WithId, the request is bound to ctx for cancellation and deadline.
+func (c *CounterpartyService) WithIdWithContext(ctx context.Context, id string) (*CounterpartyResp, error) {
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: c.options,
+		Method:  http.MethodGet,
+		Url:     fmt.Sprintf("https://b2b.revolut.com/api/1.0/counterparty/%s", id),
+		Body:    nil,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
-
 	r := &CounterpartyResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
 		return nil, err
@@ -265,25 +378,22 @@ func (c *CounterpartyService) WithId(id string) (*CounterpartyResp, error) {
 // List: This endpoint retrieves all your counterparties.
 // doc: https://revolut-engineering.github.io/api-docs/#business-api-business-api-counterparties-get-counterparties
 func (c *CounterpartyService) List() ([]*CounterpartyResp, error) {
-	if c.err != nil {
-		return nil, c.err
-	}
+	return c.ListWithContext(context.Background())
+}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodGet,
-		Url:         "https://b2b.revolut.com/api/1.0/counterparties",
-		AccessToken: c.accessToken,
-		Sandbox:     c.sandbox,
-		Body:        nil,
+// ListWithContext: same as List, the request is bound to ctx for cancellation and deadline.
+func (c *CounterpartyService) ListWithContext(ctx context.Context) ([]*CounterpartyResp, error) {
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: c.options,
+		Method:  http.MethodGet,
+		Url:     "https://b2b.revolut.com/api/1.0/counterparties",
+		Body:    nil,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
-
 	r := []*CounterpartyResp{}
 	if err := json.Unmarshal(resp, &r); err != nil {
 		return nil, err
@@ -0,0 +1,153 @@
+package business
+
+import (
+	"context"
+)
+
+// EnsureAction is the action taken by an Ensure operation.
+type EnsureAction string
+
+const (
+	// the counterparty did not exist and was created
+	EnsureAction_CREATED EnsureAction = "created"
+	// a matching counterparty existed and was returned
+	EnsureAction_EXISTING EnsureAction = "existing"
+)
+
+// EnsureNonRevolut returns the active counterparty with an account in the currency and with the bank identifiers
+// of nonRevolutCounterparty, i.e. the IBAN, the CLABE or the account number with the sort code, the routing number,
+// the IFSC or the BSB code, and creates it only when there is none. Rerunning it does not create duplicates.
+// Each call lists the counterparties, use CounterpartyIndex.EnsureNonRevolut to ensure many of them.
+func (c *CounterpartyService) EnsureNonRevolut(nonRevolutCounterparty *NonRevolutCounterpartyReq) (*CounterpartyResp, EnsureAction, error) {
+	return c.EnsureNonRevolutWithContext(context.Background(), nonRevolutCounterparty)
+}
+
+// EnsureNonRevolutWithContext: same as EnsureNonRevolut, the requests are bound to ctx for cancellation and deadline.
+func (c *CounterpartyService) EnsureNonRevolutWithContext(ctx context.Context, nonRevolutCounterparty *NonRevolutCounterpartyReq) (*CounterpartyResp, EnsureAction, error) {
+	return c.Index().EnsureNonRevolutWithContext(ctx, nonRevolutCounterparty)
+}
+
+// EnsureRevolut returns the active counterparty of the Revolut profile with the phone number (personal profiles)
+// or the email address (business profiles) of revolutCounterparty, and creates it only when there is none.
+// A revolutCounterparty without a phone number and an email address cannot be matched and is always created.
+// Each call lists the counterparties, use CounterpartyIndex.EnsureRevolut to ensure many of them.
+func (c *CounterpartyService) EnsureRevolut(revolutCounterparty *RevolutCounterpartyReq) (*CounterpartyResp, EnsureAction, error) {
+	return c.EnsureRevolutWithContext(context.Background(), revolutCounterparty)
+}
+
+// EnsureRevolutWithContext: same as EnsureRevolut, the requests are bound to ctx for cancellation and deadline.
+func (c *CounterpartyService) EnsureRevolutWithContext(ctx context.Context, revolutCounterparty *RevolutCounterpartyReq) (*CounterpartyResp, EnsureAction, error) {
+	return c.Index().EnsureRevolutWithContext(ctx, revolutCounterparty)
+}
+
+// EnsureNonRevolut: same as CounterpartyService.EnsureNonRevolut, the counterparties are searched in the index
+// and the created counterparty is added to it. The Ensure operations of an index run one at a time.
+func (i *CounterpartyIndex) EnsureNonRevolut(nonRevolutCounterparty *NonRevolutCounterpartyReq) (*CounterpartyResp, EnsureAction, error) {
+	return i.EnsureNonRevolutWithContext(context.Background(), nonRevolutCounterparty)
+}
+
+// EnsureNonRevolutWithContext: same as EnsureNonRevolut, the requests are bound to ctx for cancellation and deadline.
+func (i *CounterpartyIndex) EnsureNonRevolutWithContext(ctx context.Context, nonRevolutCounterparty *NonRevolutCounterpartyReq) (*CounterpartyResp, EnsureAction, error) {
+	// an invalid request would never match, fail before the counterparties are listed
+	if err := nonRevolutCounterparty.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	i.ensureMu.Lock()
+	defer i.ensureMu.Unlock()
+
+	found, err := i.SearchWithContext(ctx, &CounterpartyQuery{State: CounterpartyState_ACTIVE, Currency: nonRevolutCounterparty.Currency})
+	if err != nil {
+		return nil, "", err
+	}
+	for _, c := range found {
+		if matchNonRevolut(c, nonRevolutCounterparty) {
+			return c, EnsureAction_EXISTING, nil
+		}
+	}
+
+	c, err := i.service.AddNonRevolutWithContext(ctx, nonRevolutCounterparty)
+	if err != nil {
+		return nil, "", err
+	}
+	i.Add(c)
+
+	return c, EnsureAction_CREATED, nil
+}
+
+// EnsureRevolut: same as CounterpartyService.EnsureRevolut, the counterparties are searched in the index
+// and the created counterparty is added to it. The Ensure operations of an index run one at a time.
+func (i *CounterpartyIndex) EnsureRevolut(revolutCounterparty *RevolutCounterpartyReq) (*CounterpartyResp, EnsureAction, error) {
+	return i.EnsureRevolutWithContext(context.Background(), revolutCounterparty)
+}
+
+// EnsureRevolutWithContext: same as EnsureRevolut, the requests are bound to ctx for cancellation and deadline.
+func (i *CounterpartyIndex) EnsureRevolutWithContext(ctx context.Context, revolutCounterparty *RevolutCounterpartyReq) (*CounterpartyResp, EnsureAction, error) {
+	i.ensureMu.Lock()
+	defer i.ensureMu.Unlock()
+
+	if revolutCounterparty.Phone != "" || revolutCounterparty.Email != "" {
+		found, err := i.SearchWithContext(ctx, &CounterpartyQuery{
+			State:       CounterpartyState_ACTIVE,
+			ProfileType: revolutCounterparty.ProfileType,
+			Phone:       revolutCounterparty.Phone,
+			Email:       revolutCounterparty.Email,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		for _, c := range found {
+			if c.isRevolut() {
+				return c, EnsureAction_EXISTING, nil
+			}
+		}
+	}
+
+	c, err := i.service.AddRevolutWithContext(ctx, revolutCounterparty)
+	if err != nil {
+		return nil, "", err
+	}
+	i.Add(c)
+
+	return c, EnsureAction_CREATED, nil
+}
+
+// isRevolut reports whether c is a counterparty of a Revolut profile.
+func (c *CounterpartyResp) isRevolut() bool {
+	for _, a := range c.Accounts {
+		if a.Type == string(CounterpartyType_REVOLUT) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchNonRevolut reports whether c has an account in the currency of req with the same bank identifiers.
+func matchNonRevolut(c *CounterpartyResp, req *NonRevolutCounterpartyReq) bool {
+	same := func(a, b string) bool {
+		return a == "" || b == "" || compact(a) == compact(b)
+	}
+	for _, a := range c.Accounts {
+		if a.Currency != req.Currency {
+			continue
+		}
+		switch {
+		case req.Iban != "":
+			if compact(a.Iban) == compact(req.Iban) {
+				return true
+			}
+		case req.Clabe != "":
+			if compact(a.Clabe) == compact(req.Clabe) {
+				return true
+			}
+		case req.AccountNo != "":
+			if compact(a.AccountNo) == compact(req.AccountNo) && same(a.SortCode, req.SortCode) &&
+				same(a.RoutingNumber, req.RoutingNumber) && same(a.BsbCode, req.BsbCode) && same(a.Ifsc, req.Ifsc) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+package business
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ExportFormat is the file format of exported counterparties.
+type ExportFormat string
+
+const (
+	// an indented JSON array of CounterpartyResp
+	ExportFormat_JSON ExportFormat = "json"
+	// a CSV with a line per account, see WriteCounterpartiesCSV
+	ExportFormat_CSV ExportFormat = "csv"
+)
+
+// Export writes all counterparties with the details of their accounts to w, e.g. to keep a snapshot for an audit.
+func (c *CounterpartyService) Export(w io.Writer, format ExportFormat) error {
+	return c.ExportWithContext(context.Background(), w, format)
+}
+
+// ExportWithContext: same as Export, the request is bound to ctx for cancellation and deadline.
+func (c *CounterpartyService) ExportWithContext(ctx context.Context, w io.Writer, format ExportFormat) error {
+	if format != ExportFormat_JSON && format != ExportFormat_CSV {
+		return fmt.Errorf("business: unknown export format %q", format)
+	}
+
+	counterparties, err := c.ListWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if format == ExportFormat_CSV {
+		return WriteCounterpartiesCSV(w, counterparties)
+	}
+
+	return WriteCounterpartiesJSON(w, counterparties)
+}
+
+// WriteCounterpartiesJSON writes counterparties to w as an indented JSON array.
+func WriteCounterpartiesJSON(w io.Writer, counterparties []*CounterpartyResp) error {
+	if counterparties == nil {
+		counterparties = []*CounterpartyResp{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(counterparties)
+}
+
+// the columns of WriteCounterpartiesCSV, those shared with a counterparty CSV are named after the ImportField_ fields
+var exportColumns = []string{
+	"id", string(ImportField_NAME), string(ImportField_PHONE), string(ImportField_PROFILE_TYPE), string(ImportField_COUNTRY),
+	"state", "created_at", "updated_at",
+	"account_id", string(ImportField_TYPE), string(ImportField_CURRENCY), string(ImportField_BANK_COUNTRY),
+	string(ImportField_ACCOUNT_NO), string(ImportField_SORT_CODE), string(ImportField_ROUTING_NUMBER),
+	string(ImportField_IBAN), string(ImportField_BIC), string(ImportField_CLABE), string(ImportField_IFSC), string(ImportField_BSB_CODE),
+	string(ImportField_EMAIL), "recipient_charges",
+}
+
+// WriteCounterpartiesCSV writes counterparties to w as a CSV with a line per account, the columns of the counterparty
+// are repeated on each line. A counterparty without accounts has a single line with empty account columns.
+func WriteCounterpartiesCSV(w io.Writer, counterparties []*CounterpartyResp) error {
+	cw := csv.NewWriter(w)
+	cw.Write(exportColumns)
+	for _, c := range counterparties {
+		accounts := c.Accounts
+		if len(accounts) == 0 {
+			accounts = []CounterpartyRespAccount{{}}
+		}
+		for _, a := range accounts {
+			cw.Write([]string{
+				c.Id, c.Name, c.Phone, string(c.ProfileType), c.Country,
+				string(c.State), formatTime(c.CreatedAt), formatTime(c.UpdatedAt),
+				a.Id, a.Type, string(a.Currency), a.BankCountry,
+				a.AccountNo, a.SortCode, a.RoutingNumber,
+				a.Iban, a.Bic, a.Clabe, a.Ifsc, a.BsbCode,
+				a.Email, string(a.RecipientCharges),
+			})
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339Nano)
+}
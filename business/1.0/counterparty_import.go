@@ -0,0 +1,441 @@
+package business
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"github.com/rysavyvladan/go-revolut/money"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ImportField is a field of a counterparty CSV, the columns are named after the fields unless mapped by CounterpartyImport.Columns.
+type ImportField string
+
+const (
+	ImportField_TYPE           ImportField = "type"
+	ImportField_PROFILE_TYPE   ImportField = "profile_type"
+	ImportField_NAME           ImportField = "name"
+	ImportField_COMPANY_NAME   ImportField = "company_name"
+	ImportField_FIRST_NAME     ImportField = "first_name"
+	ImportField_LAST_NAME      ImportField = "last_name"
+	ImportField_BANK_COUNTRY   ImportField = "bank_country"
+	ImportField_CURRENCY       ImportField = "currency"
+	ImportField_ACCOUNT_NO     ImportField = "account_no"
+	ImportField_SORT_CODE      ImportField = "sort_code"
+	ImportField_ROUTING_NUMBER ImportField = "routing_number"
+	ImportField_IBAN           ImportField = "iban"
+	ImportField_BIC            ImportField = "bic"
+	ImportField_CLABE          ImportField = "clabe"
+	ImportField_IFSC           ImportField = "ifsc"
+	ImportField_BSB_CODE       ImportField = "bsb_code"
+	ImportField_EMAIL          ImportField = "email"
+	ImportField_PHONE          ImportField = "phone"
+	ImportField_STREET_LINE1   ImportField = "street_line1"
+	ImportField_STREET_LINE2   ImportField = "street_line2"
+	ImportField_REGION         ImportField = "region"
+	ImportField_POSTCODE       ImportField = "postcode"
+	ImportField_CITY           ImportField = "city"
+	ImportField_COUNTRY        ImportField = "country"
+)
+
+// CounterpartyImport configures CounterpartyService.Import.
+type CounterpartyImport struct {
+	// an optional mapping of the ImportField_ fields to the column headers of the CSV, e.g. {"company_name": "Supplier"},
+	// the fields which are not mapped are read from the columns named after them. The headers are matched ignoring the case.
+	Columns map[ImportField]string
+	// the field separator, detected from the header line among ',', ';' and tab when 0
+	Comma rune
+	// only compare the rows with the existing counterparties, nothing is created
+	DryRun bool
+	// the number of counterparties created at the same time, 4 when 0
+	Concurrency int
+}
+
+// ImportRowStatus is the result of importing a row.
+type ImportRowStatus string
+
+const (
+	// the row is invalid, nothing was sent
+	ImportRowStatus_INVALID ImportRowStatus = "invalid"
+	// the row has the same bank details as an earlier row of the file
+	ImportRowStatus_DUPLICATE ImportRowStatus = "duplicate"
+	// a matching counterparty exists already
+	ImportRowStatus_EXISTING ImportRowStatus = "existing"
+	// the counterparty is missing and would be created, DryRun only
+	ImportRowStatus_CREATE ImportRowStatus = "create"
+	// the counterparty was created
+	ImportRowStatus_CREATED ImportRowStatus = "created"
+	// the counterparty could not be created
+	ImportRowStatus_FAILED ImportRowStatus = "failed"
+)
+
+// ImportRow is a row of a counterparty CSV and its result.
+type ImportRow struct {
+	// the number of the record in the CSV, the header is 1
+	Line int
+	// the counterparty of a row of the type revolut
+	Revolut *RevolutCounterpartyReq
+	// the counterparty of a row of the type external, the default type
+	NonRevolut *NonRevolutCounterpartyReq
+	Status     ImportRowStatus
+	// the existing or the created counterparty
+	Counterparty *CounterpartyResp
+	// the reason of the invalid, duplicate and failed statuses
+	Err error
+}
+
+// Name returns the name of the counterparty of the row.
+func (r *ImportRow) Name() string {
+	switch {
+	case r.Revolut != nil:
+		return r.Revolut.Name
+	case r.NonRevolut == nil:
+		return ""
+	case r.NonRevolut.IndividualName != nil:
+		return strings.TrimSpace(r.NonRevolut.IndividualName.FirstName + " " + r.NonRevolut.IndividualName.LastName)
+	default:
+		return r.NonRevolut.CompanyName
+	}
+}
+
+// ParseCounterpartyCSV reads and validates the rows of a counterparty CSV, the invalid rows have the status invalid.
+// A row is a Revolut counterparty when its type is revolut, otherwise it is a non-Revolut counterparty
+// named by company_name, by first_name and last_name, or by name when these are empty, so that a CSV written
+// by WriteCounterpartiesCSV can be imported.
+// CSVs exported from spreadsheets are supported: a byte order mark is skipped, the separator is detected
+// and the values are trimmed.
+func ParseCounterpartyCSV(r io.Reader, opts *CounterpartyImport) ([]*ImportRow, error) {
+	if opts == nil {
+		opts = &CounterpartyImport{}
+	}
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+
+	cr := csv.NewReader(bytes.NewReader(b))
+	cr.Comma = opts.Comma
+	if cr.Comma == 0 {
+		cr.Comma = detectComma(b)
+	}
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("business: counterparty CSV: %w", err)
+	}
+	columns, err := importColumns(header, opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*ImportRow
+	line := 1
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("business: counterparty CSV: %w", err)
+		}
+		line++
+
+		field := func(name ImportField) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+		rows = append(rows, parseImportRow(line, field))
+	}
+}
+
+func parseImportRow(line int, field func(ImportField) string) *ImportRow {
+	row := &ImportRow{Line: line}
+	switch t := strings.ToLower(field(ImportField_TYPE)); t {
+	case string(CounterpartyType_REVOLUT):
+		row.Revolut = &RevolutCounterpartyReq{
+			ProfileType: CounterpartyProfileType(strings.ToLower(field(ImportField_PROFILE_TYPE))),
+			Name:        field(ImportField_NAME),
+			Phone:       field(ImportField_PHONE),
+			Email:       field(ImportField_EMAIL),
+		}
+		row.Err = row.Revolut.validate()
+	case "", string(CounterpartyType_EXTERNAL):
+		row.NonRevolut = &NonRevolutCounterpartyReq{
+			CompanyName:   field(ImportField_COMPANY_NAME),
+			BankCountry:   strings.ToUpper(field(ImportField_BANK_COUNTRY)),
+			Currency:      money.Currency(strings.ToUpper(field(ImportField_CURRENCY))),
+			AccountNo:     field(ImportField_ACCOUNT_NO),
+			SortCode:      field(ImportField_SORT_CODE),
+			RoutingNumber: field(ImportField_ROUTING_NUMBER),
+			Iban:          field(ImportField_IBAN),
+			Bic:           field(ImportField_BIC),
+			Clabe:         field(ImportField_CLABE),
+			Ifsc:          field(ImportField_IFSC),
+			BsbCode:       field(ImportField_BSB_CODE),
+			Email:         field(ImportField_EMAIL),
+			Phone:         field(ImportField_PHONE),
+			Address: NonRevolutCounterpartyReqAddress{
+				StreetLine1: field(ImportField_STREET_LINE1),
+				StreetLine2: field(ImportField_STREET_LINE2),
+				Region:      field(ImportField_REGION),
+				Postcode:    field(ImportField_POSTCODE),
+				City:        field(ImportField_CITY),
+				Country:     strings.ToUpper(field(ImportField_COUNTRY)),
+			},
+		}
+		if first, last := field(ImportField_FIRST_NAME), field(ImportField_LAST_NAME); first != "" || last != "" {
+			row.NonRevolut.IndividualName = &NonRevolutCounterpartyReqIndividualName{FirstName: first, LastName: last}
+		} else if row.NonRevolut.CompanyName == "" {
+			// a CSV written by WriteCounterpartiesCSV has the name only
+			row.NonRevolut.CompanyName = field(ImportField_NAME)
+		}
+		row.Err = row.NonRevolut.Validate()
+	default:
+		row.Err = &ValidationError{Field: string(ImportField_TYPE), Message: fmt.Sprintf("%q is not revolut or external", t)}
+	}
+	if row.Err != nil {
+		row.Status = ImportRowStatus_INVALID
+	}
+
+	return row
+}
+
+// validate checks the fields required by the profile type before a CSV row is imported.
+func (r *RevolutCounterpartyReq) validate() error {
+	var errs ValidationErrors
+	switch r.ProfileType {
+	case CounterpartyProfileType_PERSONAL:
+		if r.Name == "" {
+			errs = append(errs, &ValidationError{Field: "RevolutCounterpartyReq.Name", Message: "a name is required for personal profiles"})
+		}
+		if r.Phone == "" {
+			errs = append(errs, &ValidationError{Field: "RevolutCounterpartyReq.Phone", Message: "a phone number is required for personal profiles"})
+		}
+	case CounterpartyProfileType_BUSINESS:
+		if r.Email == "" {
+			errs = append(errs, &ValidationError{Field: "RevolutCounterpartyReq.Email", Message: "an email address is required for business profiles"})
+		}
+	default:
+		errs = append(errs, &ValidationError{Field: "RevolutCounterpartyReq.ProfileType", Message: fmt.Sprintf("%q is not business or personal", r.ProfileType)})
+	}
+
+	return errs.err()
+}
+
+// Import reads the counterparties of a CSV (see ParseCounterpartyCSV), compares them with the existing ones
+// and creates the missing ones. A row matches a counterparty the same way as by EnsureNonRevolut and EnsureRevolut.
+// The status of each row is reported in the returned rows, the error is only set when the CSV could not be read
+// or the counterparties could not be listed.
+func (c *CounterpartyService) Import(r io.Reader, opts *CounterpartyImport) ([]*ImportRow, error) {
+	return c.ImportWithContext(context.Background(), r, opts)
+}
+
+// ImportWithContext: same as Import, the requests are bound to ctx for cancellation and deadline.
+// The rows not created before ctx is done fail with the error of ctx.
+func (c *CounterpartyService) ImportWithContext(ctx context.Context, r io.Reader, opts *CounterpartyImport) ([]*ImportRow, error) {
+	if opts == nil {
+		opts = &CounterpartyImport{}
+	}
+	rows, err := ParseCounterpartyCSV(r, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := c.Index()
+	if err := idx.RefreshWithContext(ctx); err != nil {
+		return nil, err
+	}
+	missing, err := idx.planWithContext(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.DryRun {
+		c.create(ctx, missing, opts.Concurrency)
+	}
+
+	return rows, nil
+}
+
+// planWithContext sets the status of the valid rows to duplicate, existing or create and returns the rows to create.
+func (i *CounterpartyIndex) planWithContext(ctx context.Context, rows []*ImportRow) ([]*ImportRow, error) {
+	var missing []*ImportRow
+	first := map[string]*ImportRow{}
+	for _, row := range rows {
+		if row.Status == ImportRowStatus_INVALID {
+			continue
+		}
+		key := row.key()
+		if f, ok := first[key]; ok {
+			row.Status, row.Err = ImportRowStatus_DUPLICATE, fmt.Errorf("business: same counterparty as line %d", f.Line)
+			continue
+		}
+		first[key] = row
+
+		found, err := i.findWithContext(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			row.Status, row.Counterparty = ImportRowStatus_EXISTING, found
+			continue
+		}
+		row.Status = ImportRowStatus_CREATE
+		missing = append(missing, row)
+	}
+
+	return missing, nil
+}
+
+// create creates the counterparties of rows, concurrency at a time (4 when 0), and sets their status
+// to created or failed. The rows not started before ctx is done fail with the error of ctx.
+func (c *CounterpartyService) create(ctx context.Context, rows []*ImportRow, concurrency int) {
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, row := range rows {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			row.Status, row.Err = ImportRowStatus_FAILED, ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(row *ImportRow) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if row.Revolut != nil {
+				row.Counterparty, row.Err = c.AddRevolutWithContext(ctx, row.Revolut)
+			} else {
+				row.Counterparty, row.Err = c.AddNonRevolutWithContext(ctx, row.NonRevolut)
+			}
+			row.Status = ImportRowStatus_CREATED
+			if row.Err != nil {
+				row.Status = ImportRowStatus_FAILED
+			}
+		}(row)
+	}
+	wg.Wait()
+}
+
+// findWithContext returns the active counterparty matching row, nil when there is none.
+func (i *CounterpartyIndex) findWithContext(ctx context.Context, row *ImportRow) (*CounterpartyResp, error) {
+	if row.Revolut != nil {
+		found, err := i.SearchWithContext(ctx, &CounterpartyQuery{
+			State:       CounterpartyState_ACTIVE,
+			ProfileType: row.Revolut.ProfileType,
+			Phone:       row.Revolut.Phone,
+			Email:       row.Revolut.Email,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range found {
+			if c.isRevolut() {
+				return c, nil
+			}
+		}
+		return nil, nil
+	}
+
+	found, err := i.SearchWithContext(ctx, &CounterpartyQuery{State: CounterpartyState_ACTIVE, Currency: row.NonRevolut.Currency})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range found {
+		if matchNonRevolut(c, row.NonRevolut) {
+			return c, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// key identifies the counterparty of a row to find the duplicate rows.
+func (r *ImportRow) key() string {
+	if r.Revolut != nil {
+		return strings.Join([]string{"revolut", string(r.Revolut.ProfileType), normalizePhone(r.Revolut.Phone), strings.ToLower(r.Revolut.Email)}, "|")
+	}
+
+	n := r.NonRevolut
+	return strings.Join([]string{"external", string(n.Currency), compact(n.Iban), compact(n.Clabe), compact(n.AccountNo),
+		compact(n.SortCode), compact(n.RoutingNumber), compact(n.BsbCode), compact(n.Ifsc)}, "|")
+}
+
+// WriteImportReport writes a CSV with the line, the name, the status, the counterparty ID and the error of each row.
+func WriteImportReport(w io.Writer, rows []*ImportRow) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"line", "name", "status", "counterparty_id", "error"})
+	for _, row := range rows {
+		var id, msg string
+		if row.Counterparty != nil {
+			id = row.Counterparty.Id
+		}
+		if row.Err != nil {
+			msg = row.Err.Error()
+		}
+		cw.Write([]string{strconv.Itoa(row.Line), row.Name(), string(row.Status), id, msg})
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// importColumns returns the index of the column of each field.
+func importColumns(header []string, mapping map[ImportField]string) (map[ImportField]int, error) {
+	byHeader := map[string]int{}
+	for i, h := range header {
+		byHeader[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	columns := map[ImportField]int{}
+	for h, i := range byHeader {
+		columns[ImportField(h)] = i
+	}
+	for f, h := range mapping {
+		i, ok := byHeader[strings.ToLower(strings.TrimSpace(h))]
+		if !ok {
+			return nil, fmt.Errorf("business: counterparty CSV: no column %q for the field %s", h, f)
+		}
+		columns[f] = i
+	}
+
+	return columns, nil
+}
+
+// detectComma returns the most frequent of ',', ';' and tab in the first line of b.
+func detectComma(b []byte) rune {
+	line := b
+	if i := bytes.IndexByte(b, '\n'); i >= 0 {
+		line = b[:i]
+	}
+
+	comma, most := ',', 0
+	for _, c := range []rune{',', ';', '\t'} {
+		if n := bytes.Count(line, []byte(string(c))); n > most {
+			comma, most = c, n
+		}
+	}
+
+	return comma
+}
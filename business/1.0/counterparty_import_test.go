@@ -0,0 +1,120 @@
+package business
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCounterpartyCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		opts *CounterpartyImport
+	}{
+		{"comma", "company_name,bank_country,currency,account_no,sort_code\nAcme Ltd,GB,GBP,31926819,60-16-13\n", nil},
+		{"semicolon", "company_name;bank_country;currency;account_no;sort_code\nAcme Ltd;gb;gbp;31926819;60-16-13\n", nil},
+		{"tab", "company_name\tbank_country\tcurrency\taccount_no\tsort_code\nAcme Ltd\tGB\tGBP\t31926819\t60-16-13\n", nil},
+		{"byte order mark", "\xef\xbb\xbfcompany_name,bank_country,currency,account_no,sort_code\r\nAcme Ltd,GB,GBP,31926819,60-16-13\r\n", nil},
+		{"spaces and case", "Company_Name, Bank_Country, CURRENCY, account_no, sort_code\n Acme Ltd , GB, GBP, 31926819, 60-16-13\n", nil},
+		{"a comma in a semicolon CSV", "company_name;bank_country;currency;account_no;sort_code\n\"Acme, Ltd\";GB;GBP;31926819;60-16-13\n", nil},
+		{
+			"mapped columns", "Supplier,Country,Ccy,Account,Sort code,bank_country\nAcme Ltd,FR,GBP,31926819,60-16-13,GB\n",
+			&CounterpartyImport{Columns: map[ImportField]string{
+				ImportField_COMPANY_NAME: "supplier", ImportField_CURRENCY: "Ccy", ImportField_ACCOUNT_NO: "Account", ImportField_SORT_CODE: "SORT CODE",
+			}},
+		},
+		{"explicit separator", "company_name|bank_country|currency|account_no|sort_code\nAcme Ltd|GB|GBP|31926819|60-16-13\n", &CounterpartyImport{Comma: '|'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ParseCounterpartyCSV(strings.NewReader(tt.csv), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 1 {
+				t.Fatalf("got %d rows, want 1", len(rows))
+			}
+			row := rows[0]
+			if row.Err != nil || row.Status != "" {
+				t.Fatalf("got the status %q and %v, want a valid row", row.Status, row.Err)
+			}
+			n := row.NonRevolut
+			if n == nil || n.BankCountry != "GB" || n.Currency != "GBP" || n.AccountNo != "31926819" || n.SortCode != "60-16-13" {
+				t.Errorf("got the counterparty %+v", n)
+			}
+			if name := row.Name(); name != "Acme Ltd" && name != "Acme, Ltd" {
+				t.Errorf("got the name %q", name)
+			}
+			if row.Line != 2 {
+				t.Errorf("got the line %d, want 2", row.Line)
+			}
+		})
+	}
+}
+
+func TestParseCounterpartyCSVRows(t *testing.T) {
+	csv := "type,profile_type,name,phone,email,company_name,first_name,last_name,bank_country,currency,iban,bic\n" +
+		"revolut,personal,John Smith,+4412345678,,,,,,,,\n" +
+		",,,,,,,,,,,\n" +
+		"external,,,,,,Hans,Muller,DE,EUR,DE89370400440532013000,COBADEFFXXX\n" +
+		",,Acme GmbH,,,,,,DE,EUR,DE89370400440532013000,COBADEFFXXX\n" +
+		"revolut,business,Acme Ltd,,,,,,,,,\n" +
+		"iban,,Acme SA,,,,,,FR,EUR,FR1420041010050500013M02606,BNPAFRPP\n" +
+		"external,,,,,Acme BV,,,NL,EUR,NL91ABNA0417164301,ABNANL2A\n"
+	rows, err := ParseCounterpartyCSV(strings.NewReader(csv), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		line    int
+		name    string
+		revolut bool
+		invalid string
+	}{
+		{2, "John Smith", true, ""},
+		// the record without values is skipped but counted
+		{4, "Hans Muller", false, ""},
+		{5, "Acme GmbH", false, ""},
+		{6, "Acme Ltd", true, "RevolutCounterpartyReq.Email"},
+		{7, "", false, "type"},
+		{8, "Acme BV", false, "NonRevolutCounterpartyReq.Iban"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(want))
+	}
+	for i, w := range want {
+		row := rows[i]
+		if row.Line != w.line || row.Name() != w.name || (row.Revolut != nil) != w.revolut {
+			t.Errorf("row %d: got the line %d, the name %q and revolut %v, want %d, %q and %v", i, row.Line, row.Name(), row.Revolut != nil, w.line, w.name, w.revolut)
+		}
+		if w.invalid == "" {
+			if row.Status != "" || row.Err != nil {
+				t.Errorf("line %d: got the status %q and %v, want a valid row", row.Line, row.Status, row.Err)
+			}
+			continue
+		}
+		if row.Status != ImportRowStatus_INVALID || row.Err == nil || !strings.Contains(row.Err.Error(), w.invalid) {
+			t.Errorf("line %d: got the status %q and %v, want an invalid %s", row.Line, row.Status, row.Err, w.invalid)
+		}
+	}
+}
+
+func TestParseCounterpartyCSVErrors(t *testing.T) {
+	// a mapped column must exist
+	_, err := ParseCounterpartyCSV(strings.NewReader("company_name,currency\nAcme Ltd,GBP\n"), &CounterpartyImport{Columns: map[ImportField]string{ImportField_COMPANY_NAME: "Supplier"}})
+	if err == nil {
+		t.Error("got no error for a missing mapped column")
+	}
+
+	// a malformed CSV
+	if _, err := ParseCounterpartyCSV(strings.NewReader("company_name,currency\n\"Acme Ltd,GBP\n"), nil); err == nil {
+		t.Error("got no error for an unterminated quote")
+	}
+
+	// an empty file has no rows
+	rows, err := ParseCounterpartyCSV(strings.NewReader("\xef\xbb\xbf"), nil)
+	if err != nil || len(rows) != 0 {
+		t.Errorf("got %d rows and %v, want none", len(rows), err)
+	}
+}
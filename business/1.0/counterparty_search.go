@@ -0,0 +1,329 @@
+package business
+
+import (
+	"context"
+	"github.com/rysavyvladan/go-revolut/money"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// CounterpartyQuery selects counterparties, the empty fields are ignored and a counterparty must match all the others.
+type CounterpartyQuery struct {
+	// a part of the name, ignoring the case, the diacritics and the repeated spaces, e.g. "muller" matches "Hans Müller"
+	Name string
+	// the phone number, ignoring everything but the digits
+	Phone string
+	// the email address of one of the accounts, ignoring the case
+	Email string
+	// the IBAN of one of the accounts, ignoring the case and the spaces
+	Iban string
+	// the account number of one of the accounts, ignoring the spaces and the dashes
+	AccountNo string
+	// the sort code of the account with AccountNo, ignoring the spaces and the dashes
+	SortCode string
+	// the type of the Revolut profile
+	ProfileType CounterpartyProfileType
+	// the state of the counterparty
+	State CounterpartyState
+	// the currency of one of the accounts
+	Currency money.Currency
+}
+
+// Match reports whether c matches all non-empty fields of q.
+func (q *CounterpartyQuery) Match(c *CounterpartyResp) bool {
+	if q.Name != "" && !strings.Contains(normalizeName(c.Name), normalizeName(q.Name)) {
+		return false
+	}
+	if q.Phone != "" && normalizePhone(c.Phone) != normalizePhone(q.Phone) {
+		return false
+	}
+	if q.ProfileType != "" && c.ProfileType != q.ProfileType {
+		return false
+	}
+	if q.State != "" && c.State != q.State {
+		return false
+	}
+
+	return q.matchAccount(c.Accounts, func(a *CounterpartyRespAccount) bool {
+		return q.Email == "" || strings.EqualFold(a.Email, q.Email)
+	}) && q.matchAccount(c.Accounts, func(a *CounterpartyRespAccount) bool {
+		return q.Iban == "" || compact(a.Iban) == compact(q.Iban)
+	}) && q.matchAccount(c.Accounts, func(a *CounterpartyRespAccount) bool {
+		return (q.AccountNo == "" || compact(a.AccountNo) == compact(q.AccountNo)) &&
+			(q.SortCode == "" || compact(a.SortCode) == compact(q.SortCode))
+	}) && q.matchAccount(c.Accounts, func(a *CounterpartyRespAccount) bool {
+		return q.Currency == "" || a.Currency == q.Currency
+	})
+}
+
+// matchAccount reports whether one of the accounts satisfies fn.
+func (q *CounterpartyQuery) matchAccount(accounts []CounterpartyRespAccount, fn func(*CounterpartyRespAccount) bool) bool {
+	if len(accounts) == 0 {
+		return fn(&CounterpartyRespAccount{})
+	}
+	for i := range accounts {
+		if fn(&accounts[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Search returns the counterparties matching q, all counterparties are listed on each call,
+// use a CounterpartyIndex to search them repeatedly.
+func (c *CounterpartyService) Search(q *CounterpartyQuery) ([]*CounterpartyResp, error) {
+	return c.SearchWithContext(context.Background(), q)
+}
+
+// SearchWithContext: same as Search, the request is bound to ctx for cancellation and deadline.
+func (c *CounterpartyService) SearchWithContext(ctx context.Context, q *CounterpartyQuery) ([]*CounterpartyResp, error) {
+	counterparties, err := c.ListWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterCounterparties(counterparties, q), nil
+}
+
+func filterCounterparties(counterparties []*CounterpartyResp, q *CounterpartyQuery) []*CounterpartyResp {
+	var r []*CounterpartyResp
+	for _, c := range counterparties {
+		if q.Match(c) {
+			r = append(r, c)
+		}
+	}
+
+	return r
+}
+
+// CounterpartyIndex caches the counterparties for repeated searches, it is safe for concurrent use.
+// The counterparties are listed on the first search and then only by Refresh,
+// or again once MaxAge elapsed:
+//
+//	idx := bC.Counterparty().Index()
+//	found, err := idx.Search(&business.CounterpartyQuery{Iban: "DE89 3704 0044 0532 0130 00"})
+type CounterpartyIndex struct {
+	// the age after which the next search lists the counterparties again, never when 0
+	MaxAge time.Duration
+
+	service *CounterpartyService
+	mu      sync.Mutex
+	loaded  time.Time
+	all     []*CounterpartyResp
+	byId    map[string]*CounterpartyResp
+	byKey   map[string][]*CounterpartyResp
+	// serialises the Ensure operations so that concurrent calls do not create duplicates
+	ensureMu sync.Mutex
+}
+
+// Index returns an empty index of the counterparties, it is loaded by the first search.
+func (c *CounterpartyService) Index() *CounterpartyIndex {
+	return &CounterpartyIndex{service: c}
+}
+
+// Refresh lists the counterparties again.
+func (i *CounterpartyIndex) Refresh() error {
+	return i.RefreshWithContext(context.Background())
+}
+
+// RefreshWithContext: same as Refresh, the request is bound to ctx for cancellation and deadline.
+func (i *CounterpartyIndex) RefreshWithContext(ctx context.Context) error {
+	counterparties, err := i.service.ListWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.all, i.byId, i.byKey = nil, map[string]*CounterpartyResp{}, map[string][]*CounterpartyResp{}
+	for _, c := range counterparties {
+		i.add(c)
+	}
+	i.loaded = time.Now()
+
+	return nil
+}
+
+// Search returns the counterparties of the index matching q, the index is loaded when empty or older than MaxAge.
+func (i *CounterpartyIndex) Search(q *CounterpartyQuery) ([]*CounterpartyResp, error) {
+	return i.SearchWithContext(context.Background(), q)
+}
+
+// SearchWithContext: same as Search, the request is bound to ctx for cancellation and deadline.
+func (i *CounterpartyIndex) SearchWithContext(ctx context.Context, q *CounterpartyQuery) ([]*CounterpartyResp, error) {
+	if err := i.load(ctx); err != nil {
+		return nil, err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	candidates := i.all
+	// the exact identifiers narrow the candidates down without scanning all counterparties
+	switch {
+	case q.Iban != "":
+		candidates = i.byKey["iban:"+compact(q.Iban)]
+	case q.AccountNo != "":
+		candidates = i.byKey["account:"+compact(q.AccountNo)]
+	case q.Email != "":
+		candidates = i.byKey["email:"+strings.ToLower(q.Email)]
+	case q.Phone != "":
+		candidates = i.byKey["phone:"+normalizePhone(q.Phone)]
+	}
+
+	return filterCounterparties(candidates, q), nil
+}
+
+// Get returns the counterparty of the index with the ID id, nil when it is not in the index.
+func (i *CounterpartyIndex) Get(id string) *CounterpartyResp {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.byId[id]
+}
+
+// Add adds or replaces a counterparty, e.g. one just created, without listing the counterparties again.
+func (i *CounterpartyIndex) Add(c *CounterpartyResp) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.byId == nil {
+		i.byId, i.byKey = map[string]*CounterpartyResp{}, map[string][]*CounterpartyResp{}
+	}
+	i.remove(c.Id)
+	i.add(c)
+}
+
+// Remove removes the counterparty with the ID id, e.g. one just deleted.
+func (i *CounterpartyIndex) Remove(id string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(id)
+}
+
+func (i *CounterpartyIndex) load(ctx context.Context) error {
+	i.mu.Lock()
+	fresh := !i.loaded.IsZero() && (i.MaxAge == 0 || time.Since(i.loaded) < i.MaxAge)
+	i.mu.Unlock()
+	if fresh {
+		return nil
+	}
+
+	return i.RefreshWithContext(ctx)
+}
+
+func (i *CounterpartyIndex) add(c *CounterpartyResp) {
+	i.all = append(i.all, c)
+	i.byId[c.Id] = c
+	for _, k := range counterpartyKeys(c) {
+		i.byKey[k] = append(i.byKey[k], c)
+	}
+}
+
+func (i *CounterpartyIndex) remove(id string) {
+	c, ok := i.byId[id]
+	if !ok {
+		return
+	}
+	delete(i.byId, id)
+	i.all = without(i.all, c)
+	for _, k := range counterpartyKeys(c) {
+		if i.byKey[k] = without(i.byKey[k], c); len(i.byKey[k]) == 0 {
+			delete(i.byKey, k)
+		}
+	}
+}
+
+// counterpartyKeys returns the exact identifiers of c indexed by a CounterpartyIndex.
+func counterpartyKeys(c *CounterpartyResp) []string {
+	keys := map[string]bool{}
+	if c.Phone != "" {
+		keys["phone:"+normalizePhone(c.Phone)] = true
+	}
+	for _, a := range c.Accounts {
+		if a.Iban != "" {
+			keys["iban:"+compact(a.Iban)] = true
+		}
+		if a.AccountNo != "" {
+			keys["account:"+compact(a.AccountNo)] = true
+		}
+		if a.Email != "" {
+			keys["email:"+strings.ToLower(a.Email)] = true
+		}
+	}
+
+	r := make([]string, 0, len(keys))
+	for k := range keys {
+		r = append(r, k)
+	}
+
+	return r
+}
+
+func without(counterparties []*CounterpartyResp, c *CounterpartyResp) []*CounterpartyResp {
+	r := counterparties[:0:0]
+	for _, o := range counterparties {
+		if o != c {
+			r = append(r, o)
+		}
+	}
+
+	return r
+}
+
+// the Latin letters with diacritics folded to their base letters by normalizeName
+var foldedLetters = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+	'ķ': "k", 'ĺ': "l", 'ļ': "l", 'ľ': "l", 'ł': "l", 'ñ': "n", 'ń': "n", 'ņ': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ŕ': "r", 'ř': "r", 'ś': "s", 'ş': "s", 'š': "s", 'ș': "s", 'ß': "ss", 'ţ': "t", 'ť': "t", 'ț': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// normalizeName lowers the case, folds the diacritics and collapses the punctuation and the spaces of a name,
+// e.g. "  Jiří  Dvořák-Nový " becomes "jiri dvorak novy".
+func normalizeName(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// a combining mark of a decomposed letter
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			if f, ok := foldedLetters[r]; ok {
+				b.WriteString(f)
+			} else {
+				b.WriteRune(r)
+			}
+		default:
+			space = true
+		}
+	}
+
+	return b.String()
+}
+
+// normalizePhone keeps the digits of a phone number, a leading 00 is the same as +.
+func normalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+
+	return strings.TrimPrefix(b.String(), "00")
+}
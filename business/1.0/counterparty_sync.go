@@ -0,0 +1,162 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrDeletesNotConfirmed is returned by ApplySync for a plan deleting counterparties without a confirmation.
+var ErrDeletesNotConfirmed = errors.New("business: the plan deletes counterparties, confirm the deletes to apply it")
+
+// SyncPlan are the changes making the counterparties match a desired state, see CounterpartyService.PlanSync.
+type SyncPlan struct {
+	// the rows of the desired state with the status existing, create, duplicate or invalid,
+	// after ApplySync the rows to create have the status created or failed
+	Rows []*ImportRow
+	// the active counterparties which are not in the desired state
+	Deletes []*SyncDelete
+
+	concurrency int
+}
+
+// SyncDelete is a counterparty deleted by a SyncPlan.
+type SyncDelete struct {
+	Counterparty *CounterpartyResp
+	// set by ApplySync when the counterparty was deleted
+	Deleted bool
+	// the reason the counterparty could not be deleted
+	Err error
+}
+
+// Creates returns the rows of the counterparties to create.
+func (p *SyncPlan) Creates() []*ImportRow {
+	return p.rows(ImportRowStatus_CREATE, ImportRowStatus_CREATED, ImportRowStatus_FAILED)
+}
+
+// Invalid returns the invalid rows, a plan with invalid rows cannot be applied.
+func (p *SyncPlan) Invalid() []*ImportRow {
+	return p.rows(ImportRowStatus_INVALID)
+}
+
+// IsEmpty reports whether the counterparties match the desired state.
+func (p *SyncPlan) IsEmpty() bool {
+	return len(p.Creates()) == 0 && len(p.Deletes) == 0
+}
+
+func (p *SyncPlan) rows(statuses ...ImportRowStatus) []*ImportRow {
+	var r []*ImportRow
+	for _, row := range p.Rows {
+		for _, s := range statuses {
+			if row.Status == s {
+				r = append(r, row)
+				break
+			}
+		}
+	}
+
+	return r
+}
+
+// String describes the plan with a line per change, "+ name (line n)" for a create, "- name (id)" for a delete
+// and "! line n: error" for an invalid row, followed by the number of changes.
+func (p *SyncPlan) String() string {
+	var b strings.Builder
+	for _, row := range p.Creates() {
+		fmt.Fprintf(&b, "+ %s (line %d)\n", row.Name(), row.Line)
+	}
+	for _, d := range p.Deletes {
+		fmt.Fprintf(&b, "- %s (%s)\n", d.Counterparty.Name, d.Counterparty.Id)
+	}
+	invalid := p.Invalid()
+	for _, row := range invalid {
+		fmt.Fprintf(&b, "! line %d: %s\n", row.Line, row.Err)
+	}
+	fmt.Fprintf(&b, "Plan: %d to create, %d to delete, %d invalid.", len(p.Creates()), len(p.Deletes), len(invalid))
+
+	return b.String()
+}
+
+// PlanSync compares the desired state, a counterparty CSV (see ParseCounterpartyCSV), with the counterparties
+// and plans the creation of the missing ones and the deletion of the active ones which are not in the desired state.
+// A row matches a counterparty the same way as by EnsureNonRevolut and EnsureRevolut. Nothing is changed until
+// the plan is applied by ApplySync, opts.DryRun is ignored.
+func (c *CounterpartyService) PlanSync(r io.Reader, opts *CounterpartyImport) (*SyncPlan, error) {
+	return c.PlanSyncWithContext(context.Background(), r, opts)
+}
+
+// PlanSyncWithContext: same as PlanSync, the request is bound to ctx for cancellation and deadline.
+func (c *CounterpartyService) PlanSyncWithContext(ctx context.Context, r io.Reader, opts *CounterpartyImport) (*SyncPlan, error) {
+	if opts == nil {
+		opts = &CounterpartyImport{}
+	}
+	rows, err := ParseCounterpartyCSV(r, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := c.Index()
+	if err := idx.RefreshWithContext(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := idx.planWithContext(ctx, rows); err != nil {
+		return nil, err
+	}
+
+	keep := map[string]bool{}
+	for _, row := range rows {
+		if row.Status == ImportRowStatus_EXISTING {
+			keep[row.Counterparty.Id] = true
+		}
+	}
+	plan := &SyncPlan{Rows: rows, concurrency: opts.Concurrency}
+	found, err := idx.SearchWithContext(ctx, &CounterpartyQuery{State: CounterpartyState_ACTIVE})
+	if err != nil {
+		return nil, err
+	}
+	for _, cp := range found {
+		if !keep[cp.Id] {
+			plan.Deletes = append(plan.Deletes, &SyncDelete{Counterparty: cp})
+		}
+	}
+
+	return plan, nil
+}
+
+// ApplySync creates and deletes the counterparties of plan. It fails before any change when the plan has invalid
+// rows, as their counterparties would be deleted, or when it deletes counterparties and confirmDeletes is not set.
+// The result of each change is reported in the plan, an error is returned when any of them failed.
+func (c *CounterpartyService) ApplySync(plan *SyncPlan, confirmDeletes bool) error {
+	return c.ApplySyncWithContext(context.Background(), plan, confirmDeletes)
+}
+
+// ApplySyncWithContext: same as ApplySync, the requests are bound to ctx for cancellation and deadline.
+func (c *CounterpartyService) ApplySyncWithContext(ctx context.Context, plan *SyncPlan, confirmDeletes bool) error {
+	if invalid := plan.Invalid(); len(invalid) > 0 {
+		return fmt.Errorf("business: the desired state has %d invalid rows, the first on line %d: %w", len(invalid), invalid[0].Line, invalid[0].Err)
+	}
+	if len(plan.Deletes) > 0 && !confirmDeletes {
+		return ErrDeletesNotConfirmed
+	}
+
+	c.create(ctx, plan.rows(ImportRowStatus_CREATE), plan.concurrency)
+	failed := len(plan.rows(ImportRowStatus_FAILED))
+
+	for _, d := range plan.Deletes {
+		if d.Deleted {
+			continue
+		}
+		if d.Err = c.DeleteWithContext(ctx, d.Counterparty.Id); d.Err != nil {
+			failed++
+			continue
+		}
+		d.Deleted = true
+	}
+	if failed > 0 {
+		return fmt.Errorf("business: %d changes of the plan failed", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package business
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// Date is a calendar day used by the API for scheduled payments, encoded as YYYY-MM-DD.
+// The zero Date means no date.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewDate returns the date of the given day, out of range values are normalised the same way as by time.Date.
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// DateOf returns the date of t in its location.
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// ParseDate parses a date in the YYYY-MM-DD format.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, err
+	}
+
+	return DateOf(t), nil
+}
+
+// IsZero reports whether d is the zero Date.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// Before reports whether d is a day before o.
+func (d Date) Before(o Date) bool {
+	return d.Time(time.UTC).Before(o.Time(time.UTC))
+}
+
+// Time returns the midnight starting d in loc.
+func (d Date) Time(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a date, a date and time (the time is dropped), an empty string or null.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		*d = Date{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*d = Date{}
+		return nil
+	}
+	if len(s) > len(dateLayout) {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		*d = DateOf(t)
+		return nil
+	}
+
+	date, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = date
+
+	return nil
+}
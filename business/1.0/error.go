@@ -0,0 +1,108 @@
+package business
+
+import (
+	"errors"
+	"fmt"
+	"github.com/rysavyvladan/go-revolut/business/1.0/request"
+	"github.com/rysavyvladan/go-revolut/money"
+	"strings"
+)
+
+// APIError is returned by every service method when the Business API responds with a non-2xx status.
+// Use errors.As to access the status, the Revolut error code and the raw body.
+type APIError = request.Error
+
+// NetworkError is returned by every service method when no response was received, e.g. on a timeout.
+type NetworkError = request.NetworkError
+
+var (
+	// ErrNotFound matches an APIError with status 404
+	ErrNotFound = request.ErrNotFound
+	// ErrUnauthorized matches an APIError with status 401
+	ErrUnauthorized = request.ErrUnauthorized
+	// ErrRateLimited matches an APIError with status 429
+	ErrRateLimited = request.ErrRateLimited
+	// ErrValidation matches an APIError with status 400 or 422
+	ErrValidation = request.ErrValidation
+)
+
+// IsNotFound reports whether err is an APIError caused by a missing resource.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsUnauthorized reports whether err is an APIError caused by a missing or expired access token.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsRateLimited reports whether err is an APIError caused by exceeding the rate limit.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsValidation reports whether err is an APIError or a ValidationError caused by an invalid request.
+func IsValidation(err error) bool {
+	return errors.Is(err, ErrValidation)
+}
+
+// ValidationError is returned before a request is sent when it is invalid, it matches ErrValidation
+// so that it is handled the same way as an APIError with status 400.
+type ValidationError struct {
+	// the name of the invalid field, e.g. TransactionReq.From
+	Field string
+	// the reason the field is invalid
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return "business: " + e.Field + ": " + e.Message
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// ValidationErrors is returned before a request is sent when several of its fields are invalid,
+// it matches ErrValidation and each *ValidationError is available in the slice.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, v := range e {
+		msgs[i] = v.Field + ": " + v.Message
+	}
+
+	return "business: " + strings.Join(msgs, "; ")
+}
+
+func (e ValidationErrors) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// err returns nil when there are no errors, so that a nil ValidationErrors is not returned as a non-nil error.
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// validateCurrency fails when c is not an ISO 4217 currency code.
+func validateCurrency(field string, c money.Currency) error {
+	if c.Validate() != nil {
+		return &ValidationError{Field: field, Message: fmt.Sprintf("%q is not an ISO 4217 currency code", string(c))}
+	}
+
+	return nil
+}
+
+// validateAmount fails when d has more decimal places than c allows, c is expected to be valid.
+func validateAmount(field string, d money.Decimal, c money.Currency) error {
+	if _, err := money.ToMinor(d, c); err != nil {
+		return &ValidationError{Field: field, Message: fmt.Sprintf("%s has more than the %d decimal places of %s", d, c.MinorUnits(), c)}
+	}
+
+	return nil
+}
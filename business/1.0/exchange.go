@@ -1,29 +1,27 @@
 package business
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/rysavyvladan/go-revolut/business/1.0/request"
+	"github.com/rysavyvladan/go-revolut/money"
 	"net/http"
 	"net/url"
 	"time"
 )
 
 type ExchangeService struct {
-	accessToken string
-	sandbox     bool
-
-	err error
+	options *request.Options
 }
 
 type ExchangeRateReq struct {
 	// the currency you would like to exchange from
-	From string
+	From money.Currency
 	// the currency you would like to exchange to
-	To string
+	To money.Currency
 	// exchange amount, default is 1.00
-	Amount float64
+	Amount money.Decimal
 }
 
 type ExchangeRateResp struct {
@@ -32,7 +30,7 @@ type ExchangeRateResp struct {
 	// information about the currency to exchange to
 	To Amount `json:"to"`
 	// exchange rate
-	Rate float64 `json:"rate"`
+	Rate money.Decimal `json:"rate"`
 	// fee for the operation
 	Fee Amount `json:"fee"`
 	// date of proposed exchange rate
@@ -40,8 +38,13 @@ type ExchangeRateResp struct {
 }
 
 type Amount struct {
-	Amount   float64 `json:"amount"`
-	Currency string  `json:"currency"`
+	Amount   money.Decimal  `json:"amount"`
+	Currency money.Currency `json:"currency"`
+}
+
+// String formats the amount for display, e.g. "1,234.50 GBP".
+func (a Amount) String() string {
+	return money.Format(a.Amount, a.Currency)
 }
 
 type ExchangeReq struct {
@@ -51,14 +54,15 @@ type ExchangeReq struct {
 	To ExchangeAmount `json:"to"`
 	// a user-provided exchange reference
 	Reference string `json:"reference"`
-	// a unique value used to handle duplicates submitted as a result of lost connection or another client error (40 characters max)
+	// a unique value used to handle duplicates submitted as a result of lost connection or another client error (40 characters max),
+	// generated by Exchange when empty
 	RequestId string `json:"request_id"`
 }
 type ExchangeAmount struct {
 	// the account ID
-	AccountId string  `json:"account_id"`
-	Amount    float64 `json:"amount,omitempty"`
-	Currency  string  `json:"currency"`
+	AccountId string         `json:"account_id"`
+	Amount    *money.Decimal `json:"amount,omitempty"`
+	Currency  money.Currency `json:"currency"`
 }
 
 type ExchangeResp struct {
@@ -74,30 +78,69 @@ type ExchangeResp struct {
 	CompletedAt time.Time `json:"completed_at"`
 }
 
+// Validate checks the currencies and the decimal places of the amount, it is called by Rate.
+func (e *ExchangeRateReq) Validate() error {
+	if err := validateCurrency("ExchangeRateReq.From", e.From); err != nil {
+		return err
+	}
+	if err := validateCurrency("ExchangeRateReq.To", e.To); err != nil {
+		return err
+	}
+
+	return validateAmount("ExchangeRateReq.Amount", e.Amount, e.From)
+}
+
+// Validate checks the length of the request ID, the currencies and the decimal places of the amounts, it is called by Exchange.
+func (e *ExchangeReq) Validate() error {
+	if err := validateRequestId("ExchangeReq.RequestId", e.RequestId); err != nil {
+		return err
+	}
+	if err := validateCurrency("ExchangeReq.From.Currency", e.From.Currency); err != nil {
+		return err
+	}
+	if err := validateCurrency("ExchangeReq.To.Currency", e.To.Currency); err != nil {
+		return err
+	}
+	if e.From.Amount != nil {
+		if err := validateAmount("ExchangeReq.From.Amount", *e.From.Amount, e.From.Currency); err != nil {
+			return err
+		}
+	}
+	if e.To.Amount != nil {
+		if err := validateAmount("ExchangeReq.To.Amount", *e.To.Amount, e.To.Currency); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Rate:
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#exchanges-get-exchange-rates
 func (e *ExchangeService) Rate(exchangeRateReq *ExchangeRateReq) (*ExchangeRateResp, error) {
-	if e.err != nil {
-		return nil, e.err
+	return e.RateWithContext(context.Background(), exchangeRateReq)
+}
+
+// RateWithContext: same as Rate, the request is bound to ctx for cancellation and deadline.
+func (e *ExchangeService) RateWithContext(ctx context.Context, exchangeRateReq *ExchangeRateReq) (*ExchangeRateResp, error) {
+	if err := exchangeRateReq.Validate(); err != nil {
+		return nil, err
 	}
 
 	params := url.Values{}
-	params.Add("from", exchangeRateReq.From)
-	params.Add("to", exchangeRateReq.To)
-	params.Add("amount", fmt.Sprintf("%0.2f", exchangeRateReq.Amount))
-
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodGet,
-		Url:         fmt.Sprintf("https://b2b.revolut.com/api/1.0/rate?%s", params.Encode()),
-		AccessToken: e.accessToken,
-		Sandbox:     e.sandbox,
+	params.Add("from", string(exchangeRateReq.From))
+	params.Add("to", string(exchangeRateReq.To))
+	params.Add("amount", exchangeRateReq.Amount.String())
+
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: e.options,
+		Method:  http.MethodGet,
+		Url:     fmt.Sprintf("https://b2b.revolut.com/api/1.0/rate?%s", params.Encode()),
 	})
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
 
 	r := &ExchangeRateResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
@@ -108,26 +151,32 @@ func (e *ExchangeService) Rate(exchangeRateReq *ExchangeRateReq) (*ExchangeRateR
 }
 
 // Exchange: To check the exchange rate and fees for the operation, please use the /rate endpoint.
+// An empty RequestId of exchangeReq is set to a new one. When the outcome of the request is unknown, e.g. after
+// a timeout, the exchange is looked up by its RequestId and only sent again when it was not created.
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#exchanges-exchange-currency
 func (e *ExchangeService) Exchange(exchangeReq *ExchangeReq) (*ExchangeResp, error) {
-	if e.err != nil {
-		return nil, e.err
+	return e.ExchangeWithContext(context.Background(), exchangeReq)
+}
+
+// ExchangeWithContext: same as Exchange, the request is bound to ctx for cancellation and deadline.
+func (e *ExchangeService) ExchangeWithContext(ctx context.Context, exchangeReq *ExchangeReq) (*ExchangeResp, error) {
+	ensureRequestId(&exchangeReq.RequestId)
+	if err := exchangeReq.Validate(); err != nil {
+		return nil, err
 	}
 
-	resp, statusCode, err := request.New(request.Config{
+	resp, _, err := request.New(request.Config{
+		Context:     ctx,
+		Options:     e.options,
 		Method:      http.MethodPost,
 		Url:         "https://b2b.revolut.com/api/1.0/exchange",
-		AccessToken: e.accessToken,
-		Sandbox:     e.sandbox,
 		Body:        exchangeReq,
 		ContentType: request.ContentType_APPLICATION_JSON,
+		Lookup:      lookupRequestId(e.options, exchangeReq.RequestId),
 	})
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
 
 	r := &ExchangeResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
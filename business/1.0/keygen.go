@@ -0,0 +1,95 @@
+package business
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+)
+
+const (
+	defaultKeyBits             = 2048
+	defaultCertificateValidity = 5 * 365 * 24 * time.Hour
+)
+
+// KeyPairReq describes the private key and the self-signed certificate of the Business API access.
+type KeyPairReq struct {
+	// an optional size of the RSA key in bits, 2048 by default
+	Bits int
+	// the subject of the certificate, a self-signed certificate has the same issuer
+	Subject pkix.Name
+	// an optional validity of the certificate, five years by default
+	Validity time.Duration
+}
+
+// KeyPair is a private key and the certificate to be uploaded to Revolut.
+type KeyPair struct {
+	PrivateKey  *rsa.PrivateKey
+	Certificate *x509.Certificate
+	// the private key in PKCS#1 PEM encoding, as created by openssl genrsa
+	PrivateKeyPEM []byte
+	// the certificate in PEM encoding, ready to be uploaded to Revolut
+	CertificatePEM []byte
+}
+
+// GenerateKeyPair generates an RSA private key and a self-signed X.509 certificate, like
+// openssl genrsa and openssl req -new -x509 do in the setup guide.
+// doc: https://revolut-engineering.github.io/api-docs/business-api/#authentication-setting-up-access-to-your-business-account
+func GenerateKeyPair(keyPairReq *KeyPairReq) (*KeyPair, error) {
+	bits := keyPairReq.Bits
+	if bits == 0 {
+		bits = defaultKeyBits
+	}
+	validity := keyPairReq.Validity
+	if validity == 0 {
+		validity = defaultCertificateValidity
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, err
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	notBefore := time.Now().UTC()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               keyPairReq.Subject,
+		Issuer:                keyPairReq.Subject,
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyPair{
+		PrivateKey:  privateKey,
+		Certificate: certificate,
+		PrivateKeyPEM: pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+		}),
+		CertificatePEM: pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: der,
+		}),
+	}, nil
+}
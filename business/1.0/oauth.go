@@ -1,29 +1,55 @@
 package business
 
 import (
+	"context"
 	"crypto/rsa"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"github.com/dgrijalva/jwt-go"
 	"github.com/rysavyvladan/go-revolut/business/1.0/request"
+	"github.com/rysavyvladan/go-revolut/environment"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 type OAuthService struct {
-	clientId   string
-	privateKey *rsa.PrivateKey
-	issuer     string
-	sandbox    bool
+	clientId          string
+	keys              *KeyRing
+	assertionLifetime time.Duration
+	issuer            string
+	environment       environment.Environment
+	options           *request.Options
 }
 
-func NewOAuth(clientId string, privateKey *rsa.PrivateKey, issuer string, sandbox bool) *OAuthService {
+// NewOAuth creates the OAuth service signing client assertions with privateKey,
+// privateKey may be nil when the key is provided by WithSigner or WithKeyRing.
+// The sandbox environment is used when sandbox is set, WithEnvironment takes precedence.
+func NewOAuth(clientId string, privateKey *rsa.PrivateKey, issuer string, sandbox bool, opts ...Option) *OAuthService {
+	return newOAuth(clientId, privateKey, issuer, sandbox, newOptions(opts))
+}
+
+func newOAuth(clientId string, privateKey *rsa.PrivateKey, issuer string, sandbox bool, o *options) *OAuthService {
+	if o.environment == nil && sandbox {
+		WithEnvironment(environment.Sandbox)(o)
+	}
+
+	keys := o.keys
+	if keys == nil {
+		keys = NewKeyRing()
+		if o.signer != nil {
+			keys.Add(SigningKey{Signer: o.signer})
+		} else if privateKey != nil {
+			keys.Add(SigningKey{Signer: privateKey})
+		}
+	}
+
 	return &OAuthService{
-		clientId:   clientId,
-		privateKey: privateKey,
-		issuer:     issuer,
-		sandbox:    sandbox,
+		clientId:          clientId,
+		keys:              keys,
+		assertionLifetime: o.assertionLifetime,
+		issuer:            issuer,
+		environment:       o.env(),
+		options:           o.request(),
 	}
 }
 
@@ -56,15 +82,21 @@ type AuthorizationCodeResp struct {
 // ExchangeAuthorisationCode: This endpoint is used to exchange an authorisation code with an access token.
 // doc: https://revolut-engineering.github.io/api-docs/#business-api-business-api-oauth-get-authorisation-code
 func (oa *OAuthService) ExchangeAuthorisationCode(code string) (*OAuthResp, error) {
+	return oa.ExchangeAuthorisationCodeWithContext(context.Background(), code)
+}
+
+// ExchangeAuthorisationCodeWithContext: same as ExchangeAuthorisationCode, the request is bound to ctx for cancellation and deadline.
+func (oa *OAuthService) ExchangeAuthorisationCodeWithContext(ctx context.Context, code string) (*OAuthResp, error) {
 	clientAssertion, err := oa.generateClientAssertion()
 	if err != nil {
 		return nil, err
 	}
 
-	resp, statusCode, err := request.New(request.Config{
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: oa.options,
 		Method:  http.MethodPost,
 		Url:     "https://b2b.revolut.com/api/1.0/auth/token",
-		Sandbox: oa.sandbox,
 		Body: url.Values{
 			// "authorization_code"
 			"grant_type": []string{grant_type_authorization_code},
@@ -83,10 +115,6 @@ func (oa *OAuthService) ExchangeAuthorisationCode(code string) (*OAuthResp, erro
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
-
 	r := &OAuthResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
 		return nil, err
@@ -98,15 +126,21 @@ func (oa *OAuthService) ExchangeAuthorisationCode(code string) (*OAuthResp, erro
 // RefreshAccessToken: This endpoint is used to request a new user access token after the expiration date.
 // doc: https://revolut-engineering.github.io/api-docs/#business-api-business-api-oauth-refresh-access-token
 func (oa *OAuthService) RefreshAccessToken(refreshToken string) (*OAuthResp, error) {
+	return oa.RefreshAccessTokenWithContext(context.Background(), refreshToken)
+}
+
+// RefreshAccessTokenWithContext: same as RefreshAccessToken, the request is bound to ctx for cancellation and deadline.
+func (oa *OAuthService) RefreshAccessTokenWithContext(ctx context.Context, refreshToken string) (*OAuthResp, error) {
 	clientAssertion, err := oa.generateClientAssertion()
 	if err != nil {
 		return nil, err
 	}
 
-	resp, statusCode, err := request.New(request.Config{
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: oa.options,
 		Method:  http.MethodPost,
 		Url:     "https://b2b.revolut.com/api/1.0/auth/token",
-		Sandbox: oa.sandbox,
 		Body: url.Values{
 			"grant_type":            []string{grant_type_refresh_token},
 			"refresh_token":         []string{refreshToken},
@@ -120,10 +154,6 @@ func (oa *OAuthService) RefreshAccessToken(refreshToken string) (*OAuthResp, err
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
-
 	r := &OAuthResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
 		return nil, err
@@ -134,21 +164,33 @@ func (oa *OAuthService) RefreshAccessToken(refreshToken string) (*OAuthResp, err
 
 // GetAuthorisationCode: Navigate the user to this address to request an authorisation code
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#oauth-get-authorisation-code
+//
+// Deprecated: the consent page is meant for a browser and does not return JSON,
+// use AuthorisationURL or Authorise instead.
 func (oa *OAuthService) GetAuthorisationCode(clientId, redirectUri string) ([]*AuthorizationCodeResp, error) {
+	return oa.GetAuthorisationCodeWithContext(context.Background(), clientId, redirectUri)
+}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method: http.MethodGet,
-		Url:    fmt.Sprintf("https://business.revolut.com/app-confirm?client_id=%s&redirect_uri%s", clientId, redirectUri),
-		Body:   nil,
+// GetAuthorisationCodeWithContext: same as GetAuthorisationCode, the request is bound to ctx for cancellation and deadline.
+//
+// Deprecated: use AuthorisationURL or Authorise instead.
+func (oa *OAuthService) GetAuthorisationCodeWithContext(ctx context.Context, clientId, redirectUri string) ([]*AuthorizationCodeResp, error) {
+
+	// the consent page is not served by the API host the options point to
+	o := *oa.options
+	o.BaseUrl = ""
+
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: &o,
+		Method:  http.MethodGet,
+		Url:     fmt.Sprintf("%s/app-confirm?client_id=%s&redirect_uri%s", oa.environment.Url(consentHost), clientId, redirectUri),
+		Body:    nil,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
-
 	var r []*AuthorizationCodeResp
 	if err := json.Unmarshal(resp, &r); err != nil {
 		return nil, err
@@ -156,19 +198,3 @@ func (oa *OAuthService) GetAuthorisationCode(clientId, redirectUri string) ([]*A
 
 	return r, nil
 }
-
-func (oa *OAuthService) generateClientAssertion() (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256,
-		jwt.MapClaims{
-			"iss": oa.issuer,
-			"aud": aud,
-			"sub": oa.clientId,
-		})
-
-	signedToken, err := token.SignedString(oa.privateKey)
-	if err != nil {
-		return "", err
-	}
-
-	return signedToken, nil
-}
@@ -0,0 +1,161 @@
+package business
+
+import (
+	"crypto"
+	"github.com/rysavyvladan/go-revolut/business/1.0/request"
+	"github.com/rysavyvladan/go-revolut/environment"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultUserAgent = "go-revolut"
+	// the host of the API in production, see environment.Environment.Url
+	apiHost = "b2b.revolut.com"
+)
+
+// Option configures a Client or an OAuthService.
+type Option func(*options)
+
+type options struct {
+	httpClient    *http.Client
+	timeout       time.Duration
+	userAgent     string
+	environment   *environment.Environment
+	retry         *request.RetryPolicy
+	refreshMargin time.Duration
+	tokenStore    TokenStore
+
+	signer            crypto.Signer
+	keys              *KeyRing
+	assertionLifetime time.Duration
+}
+
+// RetryPolicy describes how requests failing with a network error, 429 or 5xx status are retried.
+type RetryPolicy = request.RetryPolicy
+
+// DefaultRetryPolicy retries up to 3 times waiting 0.5s, 1s and 2s (each with jitter).
+var DefaultRetryPolicy = request.DefaultRetryPolicy
+
+// WithHTTPClient sets the HTTP client used for every request, e.g. with a proxy,
+// a custom TLS config or an instrumented http.RoundTripper.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets a time limit for every request including reading of the response body.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request.
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithEnvironment selects the environment the requests are sent to, environment.Production by default.
+func WithEnvironment(env environment.Environment) Option {
+	return func(o *options) {
+		o.environment = &env
+	}
+}
+
+// WithBaseURL sends every request to baseUrl instead of the Revolut host, e.g. to an egress proxy or a fake server.
+// It is a shorthand for WithEnvironment(environment.Custom(baseUrl)).
+func WithBaseURL(baseUrl string) Option {
+	return WithEnvironment(environment.Custom(baseUrl))
+}
+
+// WithRetry enables retries of GET requests and of payments, transfers and exchanges. A payment, a transfer
+// or an exchange whose outcome is unknown is looked up by its RequestId and only sent again when it was not created.
+// A Retry-After header of the response takes precedence over the backoff of the policy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = &policy
+	}
+}
+
+// WithRefreshMargin sets how long before its expiration the access token is refreshed, one minute by default.
+func WithRefreshMargin(margin time.Duration) Option {
+	return func(o *options) {
+		o.refreshMargin = margin
+	}
+}
+
+// WithTokenStore persists the tokens of the client in store. A valid access token found in the store is used
+// without a refresh and the refresh token of the store takes precedence over the one passed to NewClient,
+// as Revolut may have rotated it.
+func WithTokenStore(store TokenStore) Option {
+	return func(o *options) {
+		o.tokenStore = store
+	}
+}
+
+// WithSigner signs client assertions with signer instead of the private key, e.g. a key held by a KMS.
+func WithSigner(signer crypto.Signer) Option {
+	return func(o *options) {
+		o.signer = signer
+	}
+}
+
+// WithKeyRing signs client assertions with the active key of keys, it takes precedence over WithSigner and the private key.
+func WithKeyRing(keys *KeyRing) Option {
+	return func(o *options) {
+		o.keys = keys
+	}
+}
+
+// WithAssertionLifetime sets how long a client assertion is valid, five minutes by default.
+func WithAssertionLifetime(lifetime time.Duration) Option {
+	return func(o *options) {
+		o.assertionLifetime = lifetime
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		userAgent:         defaultUserAgent,
+		refreshMargin:     defaultRefreshMargin,
+		assertionLifetime: defaultAssertionLifetime,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// env returns the selected environment, environment.Production when none is selected.
+func (o *options) env() environment.Environment {
+	if o.environment == nil {
+		return environment.Production
+	}
+
+	return *o.environment
+}
+
+// request builds the transport settings shared by all services of a client.
+func (o *options) request() *request.Options {
+	httpClient := o.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	if o.timeout > 0 {
+		c := *httpClient
+		c.Timeout = o.timeout
+		httpClient = &c
+	}
+
+	return &request.Options{
+		HttpClient: httpClient,
+		UserAgent:  o.userAgent,
+		BaseUrl:    o.env().Url(apiHost),
+		Retry:      o.retry,
+	}
+}
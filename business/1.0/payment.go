@@ -1,37 +1,35 @@
 package business
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/rysavyvladan/go-revolut/business/1.0/request"
+	"github.com/rysavyvladan/go-revolut/money"
 	"net/http"
 	"net/url"
 	"time"
 )
 
 type PaymentService struct {
-	accessToken string
-	sandbox     bool
-
-	err error
+	options *request.Options
 }
 
 type PaymentReq struct {
-	// the client provided ID of the transaction (40 characters max)
+	// the client provided ID of the transaction (40 characters max), generated by Create when empty
 	RequestId string `json:"request_id"`
 	// the ID of the account to pay from
 	AccountId string          `json:"account_id"`
 	Receiver  PaymentReceiver `json:"receiver"`
 	// the transaction amount
-	Amount float64 `json:"amount"`
+	Amount money.Decimal `json:"amount"`
 	// the transaction currency
-	Currency string `json:"currency"`
+	Currency money.Currency `json:"currency"`
 	// an optional textual reference shown on the transaction
 	Reference string `json:"reference,omitempty"`
-	// a future date/time
+	// an optional future date to schedule the payment for
 	// doc: https://revolut-engineering.github.io/api-docs/business-api/#payments-schedule-payment
-	ScheduleFor string `json:"schedule_for,omitempty"`
+	ScheduleFor *Date `json:"schedule_for,omitempty"`
 }
 
 type PaymentReceiver struct {
@@ -87,7 +85,7 @@ type TransactionResp struct {
 	// the instant when the transaction was completed, mandatory for completed state only
 	CompletedAt time.Time `json:"completed_at,omitempty"`
 	// an optional date when the transaction was scheduled for
-	ScheduledFor string `json:"scheduled_for"`
+	ScheduledFor Date `json:"scheduled_for"`
 	// a user provided payment reference
 	Reference string `json:"reference,omitempty"`
 	// the legs of transaction, there'll be 2 legs between your Revolut accounts and 1 leg in other cases
@@ -109,17 +107,17 @@ type TransactionLeg struct {
 	AccountId    string          `json:"account_id"`
 	Counterparty LegCounterparty `json:"counterparty"`
 	// the transaction amount
-	Amount float64 `json:"amount"`
+	Amount money.Decimal `json:"amount"`
 	// the transaction currency
-	Currency string `json:"currency"`
+	Currency money.Currency `json:"currency"`
 	// the billing amount for cross-currency payments
-	BillAmount float64 `json:"bill_amount"`
+	BillAmount money.Decimal `json:"bill_amount"`
 	// the billing currency for cross-currency payments
-	BillCurrency string `json:"bill_currency"`
+	BillCurrency money.Currency `json:"bill_currency"`
 	// the transaction leg purpose
 	Description string `json:"description"`
 	// a total balance of the account the transaction is associated with (optional)
-	Balance float64 `json:"balance,omitempty"`
+	Balance money.Decimal `json:"balance,omitempty"`
 }
 
 type LegCounterparty struct {
@@ -154,10 +152,10 @@ type TransactionCard struct {
 }
 
 type TransactionReq struct {
-	// an optional timestamp to query from, filtering on the created_at field
-	From string
-	// an optional timestamp to query to, filtering on the created_at field. Default is now
-	To string
+	// an optional instant to query from, filtering on the created_at field
+	From time.Time
+	// an optional instant to query to, filtering on the created_at field. Default is now
+	To time.Time
 	// an optional counterparty id
 	Counterparty string
 	// an optional number of records to return (1000 max, default is 100)
@@ -167,28 +165,46 @@ type TransactionReq struct {
 	Type PaymentType
 }
 
+// Validate checks the length of the request ID, the currency and the decimal places of the amount, it is called by Create.
+func (p *PaymentReq) Validate() error {
+	if err := validateRequestId("PaymentReq.RequestId", p.RequestId); err != nil {
+		return err
+	}
+	if err := validateCurrency("PaymentReq.Currency", p.Currency); err != nil {
+		return err
+	}
+
+	return validateAmount("PaymentReq.Amount", p.Amount, p.Currency)
+}
+
 // Create: This endpoint creates a new payment. If the payment is for another Revolut account,
 // business or personal, the transaction may be processed synchronously.
+// An empty RequestId of paymentReq is set to a new one. When the outcome of the request is unknown, e.g. after
+// a timeout, the payment is looked up by its RequestId and only sent again when it was not created.
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#payments-create-payment
 func (p *PaymentService) Create(paymentReq *PaymentReq) (*TransactionResp, error) {
-	if p.err != nil {
-		return nil, p.err
+	return p.CreateWithContext(context.Background(), paymentReq)
+}
+
+// CreateWithContext: same as Create, the request is bound to ctx for cancellation and deadline.
+func (p *PaymentService) CreateWithContext(ctx context.Context, paymentReq *PaymentReq) (*TransactionResp, error) {
+	ensureRequestId(&paymentReq.RequestId)
+	if err := paymentReq.Validate(); err != nil {
+		return nil, err
 	}
 
-	resp, statusCode, err := request.New(request.Config{
+	resp, _, err := request.New(request.Config{
+		Context:     ctx,
+		Options:     p.options,
 		Method:      http.MethodPost,
 		Url:         "https://b2b.revolut.com/api/1.0/pay",
-		AccessToken: p.accessToken,
-		Sandbox:     p.sandbox,
 		Body:        paymentReq,
 		ContentType: request.ContentType_APPLICATION_JSON,
+		Lookup:      lookupRequestId(p.options, paymentReq.RequestId),
 	})
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
 
 	r := &TransactionResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
@@ -201,22 +217,20 @@ func (p *PaymentService) Create(paymentReq *PaymentReq) (*TransactionResp, error
 // WithId: To retrieve a transaction by ID
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#payments-get-transaction
 func (p *PaymentService) WithId(id string) (*TransactionResp, error) {
-	if p.err != nil {
-		return nil, p.err
-	}
+	return p.WithIdWithContext(context.Background(), id)
+}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodGet,
-		Url:         fmt.Sprintf("https://b2b.revolut.com/api/1.0/transaction/%s", id),
-		AccessToken: p.accessToken,
-		Sandbox:     p.sandbox,
+// WithIdWithContext: same as WithId, the request is bound to ctx for cancellation and deadline.
+func (p *PaymentService) WithIdWithContext(ctx context.Context, id string) (*TransactionResp, error) {
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: p.options,
+		Method:  http.MethodGet,
+		Url:     fmt.Sprintf("https://b2b.revolut.com/api/1.0/transaction/%s", id),
 	})
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
 
 	r := &TransactionResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
@@ -229,22 +243,20 @@ func (p *PaymentService) WithId(id string) (*TransactionResp, error) {
 // WithRequestId: To retrieve a transaction by request ID
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#payments-get-transaction
 func (p *PaymentService) WithRequestId(requestId string) (*TransactionResp, error) {
-	if p.err != nil {
-		return nil, p.err
-	}
+	return p.WithRequestIdWithContext(context.Background(), requestId)
+}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodGet,
-		Url:         fmt.Sprintf("https://b2b.revolut.com/api/1.0/transaction/%s?id_type=request_id", requestId),
-		AccessToken: p.accessToken,
-		Sandbox:     p.sandbox,
+// WithRequestIdWithContext: same as WithRequestId, the request is bound to ctx for cancellation and deadline.
+func (p *PaymentService) WithRequestIdWithContext(ctx context.Context, requestId string) (*TransactionResp, error) {
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: p.options,
+		Method:  http.MethodGet,
+		Url:     fmt.Sprintf("https://b2b.revolut.com/api/1.0/transaction/%s?id_type=request_id", url.PathEscape(requestId)),
 	})
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
 
 	r := &TransactionResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
@@ -257,22 +269,20 @@ func (p *PaymentService) WithRequestId(requestId string) (*TransactionResp, erro
 // Cancel: This endpoint allows to cancel a scheduled transaction that was initiated by you, via API.
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#payments-get-transaction
 func (p *PaymentService) Cancel(id string) error {
-	if p.err != nil {
-		return p.err
-	}
+	return p.CancelWithContext(context.Background(), id)
+}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodDelete,
-		Url:         fmt.Sprintf("https://b2b.revolut.com/api/1.0/transaction/%s", id),
-		AccessToken: p.accessToken,
-		Sandbox:     p.sandbox,
+// CancelWithContext: same as Cancel, the request is bound to ctx for cancellation and deadline.
+func (p *PaymentService) CancelWithContext(ctx context.Context, id string) error {
+	_, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: p.options,
+		Method:  http.MethodDelete,
+		Url:     fmt.Sprintf("https://b2b.revolut.com/api/1.0/transaction/%s", id),
 	})
 	if err != nil {
 		return err
 	}
-	if statusCode != http.StatusNoContent {
-		return errors.New(string(resp))
-	}
 
 	return nil
 }
@@ -280,16 +290,21 @@ func (p *PaymentService) Cancel(id string) error {
 // List: This endpoint retrieves historical transactions based on the provided query criteria.
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#payments-get-transaction
 func (p *PaymentService) List(transactionReq *TransactionReq) ([]*TransactionResp, error) {
-	if p.err != nil {
-		return nil, p.err
+	return p.ListWithContext(context.Background(), transactionReq)
+}
+
+// ListWithContext: same as List, the request is bound to ctx for cancellation and deadline.
+func (p *PaymentService) ListWithContext(ctx context.Context, transactionReq *TransactionReq) ([]*TransactionResp, error) {
+	if !transactionReq.From.IsZero() && !transactionReq.To.IsZero() && !transactionReq.From.Before(transactionReq.To) {
+		return nil, &ValidationError{Field: "TransactionReq.From", Message: "must precede TransactionReq.To"}
 	}
 
 	params := url.Values{}
-	if transactionReq.From != "" {
-		params.Add("from", transactionReq.From)
+	if !transactionReq.From.IsZero() {
+		params.Add("from", transactionReq.From.UTC().Format(time.RFC3339Nano))
 	}
-	if transactionReq.To != "" {
-		params.Add("to", transactionReq.To)
+	if !transactionReq.To.IsZero() {
+		params.Add("to", transactionReq.To.UTC().Format(time.RFC3339Nano))
 	}
 	if transactionReq.Counterparty != "" {
 		params.Add("counterparty", transactionReq.Counterparty)
@@ -301,18 +316,15 @@ func (p *PaymentService) List(transactionReq *TransactionReq) ([]*TransactionRes
 		params.Add("type", string(transactionReq.Type))
 	}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodGet,
-		Url:         fmt.Sprintf("https://b2b.revolut.com/api/1.0/transactions?%s", params.Encode()),
-		AccessToken: p.accessToken,
-		Sandbox:     p.sandbox,
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: p.options,
+		Method:  http.MethodGet,
+		Url:     fmt.Sprintf("https://b2b.revolut.com/api/1.0/transactions?%s", params.Encode()),
 	})
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
 
 	r := []*TransactionResp{}
 	if err := json.Unmarshal(resp, &r); err != nil {
@@ -0,0 +1,257 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"github.com/rysavyvladan/go-revolut/money"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BatchOptions configures PaymentService.CreateBatch.
+type BatchOptions struct {
+	// the number of payments created at the same time, 4 when 0
+	Concurrency int
+	// the minimum delay between the starts of two payments, e.g. time.Second / 5 for at most 5 payments per second,
+	// no limit when 0
+	Interval time.Duration
+	// the journal of the outcomes, a MemoryPaymentJournal when nil. Use the same journal to resume a batch.
+	Journal PaymentJournal
+}
+
+// BatchResult is the outcome of a payment of a batch.
+type BatchResult struct {
+	Req *PaymentReq
+	// the recorded outcome
+	Entry *JournalEntry
+	// the outcome was recorded by an earlier run, no request was made
+	Resumed bool
+	// the reason the payment was not created, or was not recorded in the journal
+	Err error
+}
+
+// created reports whether the payment of r was created.
+func (r *BatchResult) created() bool {
+	return r.Entry != nil && r.Entry.TransactionId != ""
+}
+
+// BatchReport is the outcome of a batch of payments, the results are in the order of the requests.
+type BatchReport struct {
+	Results []*BatchResult
+}
+
+// Count returns the number of payments created in the state, the payments which were not created are returned by NotCreated.
+func (r *BatchReport) Count(state PaymentState) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.created() && res.Entry.State == state {
+			n++
+		}
+	}
+
+	return n
+}
+
+// NotCreated returns the results of the payments which were not created, they are made by running the batch again.
+func (r *BatchReport) NotCreated() []*BatchResult {
+	var results []*BatchResult
+	for _, res := range r.Results {
+		if !res.created() {
+			results = append(results, res)
+		}
+	}
+
+	return results
+}
+
+// Totals returns the sum of the amounts of the created payments which were not declined or failed, per currency.
+func (r *BatchReport) Totals() map[money.Currency]money.Decimal {
+	totals := map[money.Currency]money.Decimal{}
+	for _, res := range r.Results {
+		if !res.created() || res.Entry.State == PaymentState_DECLINE || res.Entry.State == PaymentState_FAILED {
+			continue
+		}
+		totals[res.Req.Currency] = totals[res.Req.Currency].Add(res.Req.Amount)
+	}
+
+	return totals
+}
+
+// String summarises the report, e.g. "3 payments: 1 completed, 1 pending, 0 declined, 0 failed, 1 not created
+// (1 resumed); paid 250.00 GBP".
+func (r *BatchReport) String() string {
+	resumed := 0
+	for _, res := range r.Results {
+		if res.Resumed {
+			resumed++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d payments: %d completed, %d pending, %d declined, %d failed, %d not created (%d resumed)",
+		len(r.Results), r.Count(PaymentState_COMPLETE), r.Count(PaymentState_PENDING), r.Count(PaymentState_DECLINE),
+		r.Count(PaymentState_FAILED), len(r.NotCreated()), resumed)
+
+	totals := r.Totals()
+	currencies := make([]string, 0, len(totals))
+	for c := range totals {
+		currencies = append(currencies, string(c))
+	}
+	sort.Strings(currencies)
+	for i, c := range currencies {
+		sep := ", "
+		if i == 0 {
+			sep = "; paid "
+		}
+		b.WriteString(sep + money.Format(totals[money.Currency(c)], money.Currency(c)))
+	}
+
+	return b.String()
+}
+
+// CreateBatch creates the payments of paymentReqs, opts.Concurrency at a time and at most one per opts.Interval,
+// and records the outcome of each of them in opts.Journal. opts may be nil.
+//
+// Every payment must have a RequestId which stays the same when the batch is run again, e.g. derived from
+// the invoice it pays. A payment whose transaction is recorded in the journal is not made again, the others are
+// looked up by their RequestId before they are created, so an interrupted batch is resumed by running it again
+// with the same journal.
+//
+// The error is only set when the batch could not be started, the outcome of each payment is in the report.
+func (p *PaymentService) CreateBatch(paymentReqs []*PaymentReq, opts *BatchOptions) (*BatchReport, error) {
+	return p.CreateBatchWithContext(context.Background(), paymentReqs, opts)
+}
+
+// CreateBatchWithContext: same as CreateBatch, the requests are bound to ctx for cancellation and deadline.
+// The payments not started before ctx is done fail with the error of ctx.
+func (p *PaymentService) CreateBatchWithContext(ctx context.Context, paymentReqs []*PaymentReq, opts *BatchOptions) (*BatchReport, error) {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+	journal := opts.Journal
+	if journal == nil {
+		journal = NewMemoryPaymentJournal()
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	seen := map[string]int{}
+	for i, req := range paymentReqs {
+		if req.RequestId == "" {
+			return nil, &ValidationError{Field: fmt.Sprintf("PaymentReq[%d].RequestId", i), Message: "a request ID is required to resume the batch"}
+		}
+		if j, ok := seen[req.RequestId]; ok {
+			return nil, &ValidationError{Field: fmt.Sprintf("PaymentReq[%d].RequestId", i), Message: fmt.Sprintf("%q is the request ID of PaymentReq[%d] as well", req.RequestId, j)}
+		}
+		seen[req.RequestId] = i
+	}
+
+	report := &BatchReport{Results: make([]*BatchResult, len(paymentReqs))}
+	limiter := &intervalLimiter{interval: opts.Interval}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, req := range paymentReqs {
+		res := &BatchResult{Req: req}
+		report.Results[i] = res
+
+		entry, err := journal.Load(req.RequestId)
+		if err != nil {
+			res.Err = err
+			continue
+		}
+		if entry != nil && entry.TransactionId != "" {
+			res.Entry, res.Resumed = entry, true
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			res.Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(res *BatchResult, recorded bool) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			res.Entry, res.Err = p.batchPayment(ctx, res.Req, recorded, limiter, journal)
+		}(res, entry != nil)
+	}
+	wg.Wait()
+
+	return report, nil
+}
+
+// batchPayment creates a payment of a batch unless an earlier run created it, and records the outcome.
+func (p *PaymentService) batchPayment(ctx context.Context, req *PaymentReq, recorded bool, limiter *intervalLimiter, journal PaymentJournal) (*JournalEntry, error) {
+	if err := limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	var t *TransactionResp
+	var err error
+	if recorded {
+		// an earlier run may have created it without recording the transaction
+		if t, err = p.WithRequestIdWithContext(ctx, req.RequestId); err != nil && !IsNotFound(err) {
+			return nil, err
+		}
+	}
+	if t == nil {
+		// the attempt is recorded first, so that the payment is looked up when the run is interrupted
+		if err := journal.Save(&JournalEntry{RequestId: req.RequestId, UpdatedAt: time.Now()}); err != nil {
+			return nil, err
+		}
+		t, err = p.CreateWithContext(ctx, req)
+	}
+
+	entry := &JournalEntry{RequestId: req.RequestId, UpdatedAt: time.Now()}
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.TransactionId, entry.State, entry.ReasonCode = t.Id, t.State, t.ReasonCode
+	}
+	if jerr := journal.Save(entry); jerr != nil {
+		return entry, fmt.Errorf("business: recording the payment %s: %w", req.RequestId, jerr)
+	}
+
+	return entry, err
+}
+
+// intervalLimiter spaces the starts of the payments by at least interval.
+type intervalLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// wait blocks until the next start is allowed or ctx is done.
+func (l *intervalLimiter) wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(start))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package business
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIntervalLimiter(t *testing.T) {
+	const interval = 20 * time.Millisecond
+	l := &intervalLimiter{interval: interval}
+
+	var mu sync.Mutex
+	var starts []time.Time
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.wait(context.Background()); err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			starts = append(starts, time.Now())
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	for i := 1; i < len(starts); i++ {
+		// the timers may fire a little early on some platforms
+		if d := starts[i].Sub(starts[i-1]); d < interval-5*time.Millisecond {
+			t.Errorf("the starts %d and %d are %v apart, want %v", i-1, i, d, interval)
+		}
+	}
+}
+
+func TestIntervalLimiterContext(t *testing.T) {
+	l := &intervalLimiter{interval: time.Hour}
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+
+	// without an interval only ctx is checked
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := (&intervalLimiter{}).wait(ctx); err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
@@ -1,25 +1,23 @@
 package business
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/rysavyvladan/go-revolut/business/1.0/request"
+	"github.com/rysavyvladan/go-revolut/money"
 	"net/http"
 )
 
 type PaymentDraftService struct {
-	accessToken string
-	sandbox     bool
-
-	err error
+	options *request.Options
 }
 
 type PaymentDraftReq struct {
 	// an optional title of payment
 	Title string `json:"title"`
-	// an optional future date/time
-	ScheduleFor string `json:"schedule_for"`
+	// an optional future date to schedule the payments for
+	ScheduleFor *Date `json:"schedule_for,omitempty"`
 	// a list of planned transactions
 	Payments []PaymentDraftPayment `json:"payments"`
 }
@@ -31,9 +29,9 @@ type PaymentDraftResp struct {
 
 type PaymentDraftPayment struct {
 	// the transaction currency
-	Currency string `json:"currency"`
+	Currency money.Currency `json:"currency"`
 	// the transaction amount
-	Amount int `json:"amount"`
+	Amount money.Decimal `json:"amount"`
 	// the ID of the account to pay from (must be the same for all payments json)
 	AccountId string                      `json:"account_id"`
 	Receiver  PaymentDraftPaymentReceiver `json:"receiver,omitempty"`
@@ -56,8 +54,8 @@ type PaymentDrafts struct {
 type PaymentOrder struct {
 	// the ID of the draft payment
 	Id string `json:"id"`
-	// an optional future date/time
-	ScheduledFor string `json:"scheduled_for,optional"`
+	// an optional future date the payments are scheduled for
+	ScheduledFor Date `json:"scheduled_for,optional"`
 	// an optional title of payment
 	Title string `json:"title,optional"`
 	// count of payments in current draft
@@ -65,8 +63,8 @@ type PaymentOrder struct {
 }
 
 type PaymentDraftDetail struct {
-	// an optional future date/time
-	ScheduledFor string `json:"scheduled_for"`
+	// an optional future date the payments are scheduled for
+	ScheduledFor Date `json:"scheduled_for"`
 	// an optional title of payment
 	Title string `json:"title"`
 	// a list of payments
@@ -87,8 +85,8 @@ const (
 )
 
 type PaymentDraftDetailPayment struct {
-	Id     string           `json:"id"`
-	Amount ExchangeRateResp `json:"amount"`
+	Id     string `json:"id"`
+	Amount Amount `json:"amount"`
 	// the ID of the account to pay from
 	AccountId string `json:"account_id"`
 	// an optional textual reference shown on the transaction
@@ -107,24 +105,22 @@ type PaymentDraftDetailPayment struct {
 // Create:
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#payment-drafts-create-a-payment-draft
 func (e *PaymentDraftService) Create(paymentDraftReq *PaymentDraftReq) (*PaymentDraftResp, error) {
-	if e.err != nil {
-		return nil, e.err
-	}
+	return e.CreateWithContext(context.Background(), paymentDraftReq)
+}
 
-	resp, statusCode, err := request.New(request.Config{
+// CreateWithContext: same as Create, the request is bound to ctx for cancellation and deadline.
+func (e *PaymentDraftService) CreateWithContext(ctx context.Context, paymentDraftReq *PaymentDraftReq) (*PaymentDraftResp, error) {
+	resp, _, err := request.New(request.Config{
+		Context:     ctx,
+		Options:     e.options,
 		Method:      http.MethodPost,
 		Url:         "https://b2b.revolut.com/api/1.0/payment-drafts",
-		AccessToken: e.accessToken,
-		Sandbox:     e.sandbox,
 		Body:        paymentDraftReq,
 		ContentType: request.ContentType_APPLICATION_JSON,
 	})
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
 
 	r := &PaymentDraftResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
@@ -137,22 +133,20 @@ func (e *PaymentDraftService) Create(paymentDraftReq *PaymentDraftReq) (*Payment
 // List:
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#get-payment-drafts
 func (e *PaymentDraftService) List() (*PaymentDrafts, error) {
-	if e.err != nil {
-		return nil, e.err
-	}
+	return e.ListWithContext(context.Background())
+}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodGet,
-		Url:         "https://b2b.revolut.com/api/1.0/payment-drafts",
-		AccessToken: e.accessToken,
-		Sandbox:     e.sandbox,
+// ListWithContext: same as List, the request is bound to ctx for cancellation and deadline.
+func (e *PaymentDraftService) ListWithContext(ctx context.Context) (*PaymentDrafts, error) {
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: e.options,
+		Method:  http.MethodGet,
+		Url:     "https://b2b.revolut.com/api/1.0/payment-drafts",
 	})
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
 
 	r := &PaymentDrafts{}
 	if err := json.Unmarshal(resp, r); err != nil {
@@ -165,22 +159,20 @@ func (e *PaymentDraftService) List() (*PaymentDrafts, error) {
 // WithId:
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#get-payment-drafts-get-payment-draft-by-id
 func (e *PaymentDraftService) WithId(id string) (*PaymentDraftDetailPayment, error) {
-	if e.err != nil {
-		return nil, e.err
-	}
+	return e.WithIdWithContext(context.Background(), id)
+}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodGet,
-		Url:         fmt.Sprintf("https://b2b.revolut.com/api/1.0/payment-drafts/%s", id),
-		AccessToken: e.accessToken,
-		Sandbox:     e.sandbox,
+// WithIdWithContext: same as WithId, the request is bound to ctx for cancellation and deadline.
+func (e *PaymentDraftService) WithIdWithContext(ctx context.Context, id string) (*PaymentDraftDetailPayment, error) {
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: e.options,
+		Method:  http.MethodGet,
+		Url:     fmt.Sprintf("https://b2b.revolut.com/api/1.0/payment-drafts/%s", id),
 	})
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
 
 	r := &PaymentDraftDetailPayment{}
 	if err := json.Unmarshal(resp, r); err != nil {
@@ -193,22 +185,20 @@ func (e *PaymentDraftService) WithId(id string) (*PaymentDraftDetailPayment, err
 // Delete:
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#get-payment-drafts-delete-payment-draft
 func (e *PaymentDraftService) Delete(id string) error {
-	if e.err != nil {
-		return e.err
-	}
+	return e.DeleteWithContext(context.Background(), id)
+}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodDelete,
-		Url:         fmt.Sprintf("https://b2b.revolut.com/api/1.0/payment-drafts/%s", id),
-		AccessToken: e.accessToken,
-		Sandbox:     e.sandbox,
+// DeleteWithContext: same as Delete, the request is bound to ctx for cancellation and deadline.
+func (e *PaymentDraftService) DeleteWithContext(ctx context.Context, id string) error {
+	_, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: e.options,
+		Method:  http.MethodDelete,
+		Url:     fmt.Sprintf("https://b2b.revolut.com/api/1.0/payment-drafts/%s", id),
 	})
 	if err != nil {
 		return err
 	}
-	if statusCode != http.StatusNoContent {
-		return errors.New(string(resp))
-	}
 
 	return nil
 }
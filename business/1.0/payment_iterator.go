@@ -0,0 +1,129 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// the largest page the API returns
+const maxTransactionPage = 1000
+
+// TransactionIterator walks the transactions matching a TransactionReq page by page, newest first.
+// It keeps a single page in memory:
+//
+//	it := bC.Payment().Iter(&business.TransactionReq{From: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)})
+//	for it.Next() {
+//		fmt.Println(it.Transaction().Id)
+//	}
+//	if err := it.Err(); err != nil {
+//		panic(err)
+//	}
+type TransactionIterator struct {
+	ctx     context.Context
+	payment *PaymentService
+	req     TransactionReq
+
+	page []*TransactionResp
+	cur  *TransactionResp
+	// the IDs of the previous page, the windows overlap on the created_at of its oldest transaction
+	seen map[string]bool
+	done bool
+	err  error
+}
+
+// Iter returns an iterator over all transactions matching transactionReq between its From and To,
+// Count sets the size of the requested pages (1000 by default and at most).
+// The requests are sent lazily by TransactionIterator.Next.
+func (p *PaymentService) Iter(transactionReq *TransactionReq) *TransactionIterator {
+	return p.IterWithContext(context.Background(), transactionReq)
+}
+
+// IterWithContext: same as Iter, the requests are bound to ctx for cancellation and deadline.
+func (p *PaymentService) IterWithContext(ctx context.Context, transactionReq *TransactionReq) *TransactionIterator {
+	req := *transactionReq
+	if req.Count <= 0 || req.Count > maxTransactionPage {
+		req.Count = maxTransactionPage
+	}
+
+	return &TransactionIterator{
+		ctx:     ctx,
+		payment: p,
+		req:     req,
+	}
+}
+
+// Next advances to the next transaction, it returns false when there are no more transactions or a request failed.
+func (it *TransactionIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			it.cur = nil
+			return false
+		}
+		it.fetch()
+	}
+
+	it.cur, it.page = it.page[0], it.page[1:]
+
+	return true
+}
+
+// Transaction returns the current transaction.
+func (it *TransactionIterator) Transaction() *TransactionResp {
+	return it.cur
+}
+
+// Err returns the error of the failed request, nil when the iteration completed.
+func (it *TransactionIterator) Err() error {
+	return it.err
+}
+
+// fetch requests the next window of transactions ending at To and moves To to the oldest one.
+// The next window includes the created_at of the oldest transaction, as more transactions may share it,
+// and the transactions already returned are skipped. When the window cannot shrink that way, i.e. a full page
+// shares the created_at of the window end, the same window is requested again with larger pages, up to the
+// largest page the API returns.
+func (it *TransactionIterator) fetch() {
+	page, err := it.payment.ListWithContext(it.ctx, &it.req)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	seen := make(map[string]bool, len(page))
+	for _, t := range page {
+		seen[t.Id] = true
+		if !it.seen[t.Id] {
+			it.page = append(it.page, t)
+		}
+	}
+	if len(page) < int(it.req.Count) {
+		it.done = true
+		return
+	}
+
+	oldest := page[len(page)-1].CreatedAt
+	to := oldest.Add(time.Millisecond)
+	if !it.req.To.IsZero() && !to.Before(it.req.To) {
+		if it.req.Count >= maxTransactionPage {
+			it.err = fmt.Errorf("business: more than %d transactions were created at %s, the API cannot page through them", maxTransactionPage, oldest.Format(time.RFC3339Nano))
+			return
+		}
+		if it.req.Count *= 2; it.req.Count > maxTransactionPage {
+			it.req.Count = maxTransactionPage
+		}
+		// the larger page of the same window starts with the transactions returned so far
+		for id := range it.seen {
+			seen[id] = true
+		}
+		it.seen = seen
+		return
+	}
+	if !it.req.From.IsZero() && !to.After(it.req.From) {
+		it.done = true
+		return
+	}
+
+	it.req.To = to
+	it.seen = seen
+}
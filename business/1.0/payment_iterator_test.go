@@ -0,0 +1,115 @@
+package business
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rysavyvladan/go-revolut/business/1.0/request"
+)
+
+// transactionsServer serves the transactions newest first, filtered by from and to (exclusive) like the API.
+func transactionsServer(t *testing.T, transactions []*TransactionResp) *PaymentService {
+	t.Helper()
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].CreatedAt.After(transactions[j].CreatedAt)
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var from, to time.Time
+		if v := q.Get("from"); v != "" {
+			from, _ = time.Parse(time.RFC3339Nano, v)
+		}
+		if v := q.Get("to"); v != "" {
+			to, _ = time.Parse(time.RFC3339Nano, v)
+		}
+		count, _ := strconv.Atoi(q.Get("count"))
+
+		page := []*TransactionResp{}
+		for _, tr := range transactions {
+			if !from.IsZero() && tr.CreatedAt.Before(from) || !to.IsZero() && !tr.CreatedAt.Before(to) {
+				continue
+			}
+			if len(page) < count {
+				page = append(page, tr)
+			}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(srv.Close)
+
+	return &PaymentService{options: &request.Options{BaseUrl: srv.URL}}
+}
+
+func TestIterSharedCreatedAt(t *testing.T) {
+	base := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return base.Add(time.Duration(ms) * time.Millisecond) }
+
+	tests := []struct {
+		name  string
+		times []int
+		count int32
+		want  int
+	}{
+		{"distinct", []int{1, 2, 3, 4, 5, 6, 7}, 3, 7},
+		{"shared within a page", []int{1, 2, 2, 3, 3, 3, 4}, 3, 7},
+		{"shared across pages", []int{1, 2, 3, 3, 3, 4, 5}, 3, 7},
+		{"a full page in one millisecond", []int{1, 2, 3, 3, 3, 3, 5}, 3, 7},
+		{"a full page at the window end", []int{1, 2, 3, 3, 3, 4}, 2, 6},
+		{"pages of one", []int{1, 2, 2, 2, 3, 3, 4}, 1, 7},
+		{"many in one millisecond", append([]int{1, 3}, make([]int, 50)...), 3, 52},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var transactions []*TransactionResp
+			for i, ms := range tt.times {
+				transactions = append(transactions, &TransactionResp{Id: fmt.Sprint(i), CreatedAt: at(ms)})
+			}
+			p := transactionsServer(t, transactions)
+
+			it := p.Iter(&TransactionReq{Count: tt.count})
+			got := map[string]bool{}
+			for it.Next() {
+				if got[it.Transaction().Id] {
+					t.Errorf("the transaction %s was returned twice", it.Transaction().Id)
+				}
+				got[it.Transaction().Id] = true
+			}
+			if err := it.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("got %d transactions, want %d", len(got), tt.want)
+			}
+			// the oldest transaction is never lost
+			if !got["0"] {
+				t.Error("the oldest transaction was not returned")
+			}
+		})
+	}
+}
+
+func TestIterTooManyInOneMillisecond(t *testing.T) {
+	at := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	var transactions []*TransactionResp
+	for i := 0; i <= maxTransactionPage; i++ {
+		transactions = append(transactions, &TransactionResp{Id: fmt.Sprint(i), CreatedAt: at})
+	}
+	transactions = append(transactions, &TransactionResp{Id: "older", CreatedAt: at.Add(-time.Millisecond)})
+	p := transactionsServer(t, transactions)
+
+	it := p.Iter(&TransactionReq{Count: 10})
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if it.Err() == nil {
+		t.Errorf("got %d transactions and no error, want an error", n)
+	}
+}
@@ -0,0 +1,188 @@
+package business
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalEntry is the recorded outcome of a payment of a batch, see PaymentService.CreateBatch.
+type JournalEntry struct {
+	// the request ID of the payment
+	RequestId string `json:"request_id"`
+	// the ID of the transaction, empty until the payment was created
+	TransactionId string `json:"transaction_id,omitempty"`
+	// the state of the transaction
+	State PaymentState `json:"state,omitempty"`
+	// the reason code of a declined or failed transaction
+	ReasonCode string `json:"reason_code,omitempty"`
+	// the error of the last attempt to create the payment
+	Error string `json:"error,omitempty"`
+	// the instant when the entry was recorded
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PaymentJournal records the outcome of the payments of a batch, so that an interrupted batch can be resumed
+// without paying twice.
+type PaymentJournal interface {
+	// Load returns the last entry saved for the request ID, nil when there is none
+	Load(requestId string) (*JournalEntry, error)
+	// Save records the entry, it replaces the earlier entries of the same request ID
+	Save(entry *JournalEntry) error
+}
+
+// MemoryPaymentJournal keeps the entries in memory, e.g. to resume a batch within the same process.
+type MemoryPaymentJournal struct {
+	mu      sync.Mutex
+	entries map[string]JournalEntry
+}
+
+func NewMemoryPaymentJournal() *MemoryPaymentJournal {
+	return &MemoryPaymentJournal{entries: map[string]JournalEntry{}}
+}
+
+func (j *MemoryPaymentJournal) Load(requestId string) (*JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	e, ok := j.entries[requestId]
+	if !ok {
+		return nil, nil
+	}
+
+	return &e, nil
+}
+
+func (j *MemoryPaymentJournal) Save(entry *JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.entries == nil {
+		j.entries = map[string]JournalEntry{}
+	}
+	j.entries[entry.RequestId] = *entry
+
+	return nil
+}
+
+// FilePaymentJournal appends the entries to a file as JSON lines readable only by its owner,
+// each entry is synced to the disk before the next payment is made. The file is read by the first Load.
+type FilePaymentJournal struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]JournalEntry
+	// the last line of the file has no line feed
+	truncated bool
+	// the last line of the file was not entirely written, the file is cut to its first valid bytes by the next Save
+	torn  bool
+	valid int64
+}
+
+func NewFilePaymentJournal(path string) *FilePaymentJournal {
+	return &FilePaymentJournal{
+		path: path,
+	}
+}
+
+func (j *FilePaymentJournal) Load(requestId string) (*JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.read(); err != nil {
+		return nil, err
+	}
+	e, ok := j.entries[requestId]
+	if !ok {
+		return nil, nil
+	}
+
+	return &e, nil
+}
+
+func (j *FilePaymentJournal) Save(entry *JournalEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.read(); err != nil {
+		return err
+	}
+
+	b = append(b, '\n')
+	if j.torn {
+		// the entries read by the next run must not follow an unreadable line
+		if err := os.Truncate(j.path, j.valid); err != nil {
+			return err
+		}
+		j.torn, j.truncated = false, false
+	}
+	if j.truncated {
+		b = append([]byte("\n"), b...)
+	}
+
+	f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	j.truncated = false
+	j.entries[entry.RequestId] = *entry
+
+	return nil
+}
+
+// read loads the entries of the file once, a truncated last line left by a crash is ignored
+// and removed by the next Save.
+func (j *FilePaymentJournal) read() error {
+	if j.entries != nil {
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(j.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// the next entry starts on a new line
+	j.truncated = len(b) > 0 && b[len(b)-1] != '\n'
+
+	entries := map[string]JournalEntry{}
+	lines := bytes.Split(b, []byte("\n"))
+	for n, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		var e JournalEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			if n == len(lines)-1 {
+				// the entry was not synced
+				j.torn, j.valid = true, int64(len(b)-len(line))
+				continue
+			}
+			return fmt.Errorf("business: payment journal %s line %d: %w", j.path, n+1, err)
+		}
+		entries[e.RequestId] = e
+	}
+	j.entries = entries
+
+	return nil
+}
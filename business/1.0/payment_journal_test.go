@@ -0,0 +1,91 @@
+package business
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func journalPath(t *testing.T, content string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "journal.jsonl")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestFilePaymentJournalTruncatedLine(t *testing.T) {
+	// the process was killed while the entry of invoice-2 was written
+	path := journalPath(t, `{"request_id":"invoice-1","transaction_id":"t1","state":"completed","updated_at":"2021-01-01T00:00:00Z"}`+"\n"+
+		`{"request_id":"invoice-2","transac`)
+
+	j := NewFilePaymentJournal(path)
+	e, err := j.Load("invoice-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e == nil || e.TransactionId != "t1" {
+		t.Errorf("got the entry %v of invoice-1, want the transaction t1", e)
+	}
+	if e, err := j.Load("invoice-2"); err != nil || e != nil {
+		t.Errorf("got the entry %v and %v of invoice-2, want none", e, err)
+	}
+
+	if err := j.Save(&JournalEntry{RequestId: "invoice-2", TransactionId: "t2", State: PaymentState_PENDING, UpdatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Save(&JournalEntry{RequestId: "invoice-3", UpdatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the entries saved after the truncated line are read by another run
+	j = NewFilePaymentJournal(path)
+	for id, want := range map[string]string{"invoice-1": "t1", "invoice-2": "t2", "invoice-3": ""} {
+		e, err := j.Load(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e == nil || e.TransactionId != want {
+			t.Errorf("got the entry %v of %s, want the transaction %q", e, id, want)
+		}
+	}
+}
+
+func TestFilePaymentJournalCorrupted(t *testing.T) {
+	// only the last line may be truncated
+	path := journalPath(t, `{"request_id":"invoice-1","transac`+"\n"+
+		`{"request_id":"invoice-2","transaction_id":"t2","state":"completed","updated_at":"2021-01-01T00:00:00Z"}`+"\n")
+
+	if _, err := NewFilePaymentJournal(path).Load("invoice-2"); err == nil {
+		t.Error("got no error for a corrupted line")
+	}
+}
+
+func TestFilePaymentJournalMissingFile(t *testing.T) {
+	path := filepath.Join(filepath.Dir(journalPath(t, "")), "missing.jsonl")
+
+	j := NewFilePaymentJournal(path)
+	if e, err := j.Load("invoice-1"); err != nil || e != nil {
+		t.Fatalf("got the entry %v and %v, want none", e, err)
+	}
+	if err := j.Save(&JournalEntry{RequestId: "invoice-1", TransactionId: "t1", UpdatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0600 {
+		t.Errorf("got the permissions %v, want 0600", perm)
+	}
+}
@@ -0,0 +1,229 @@
+package business
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// IsFinal reports whether s is completed, declined or failed, the states a transaction does not leave.
+func (s PaymentState) IsFinal() bool {
+	return s == PaymentState_COMPLETE || s == PaymentState_DECLINE || s == PaymentState_FAILED
+}
+
+// WaitOptions configures WaitForFinalState.
+type WaitOptions struct {
+	// the delay before the first poll, doubled after every poll, 1s when 0
+	MinInterval time.Duration
+	// the upper bound of the delay between two polls, 30s when 0
+	MaxInterval time.Duration
+	// how long a 404 of a transaction not visible yet is polled through, 10s when 0
+	NotFoundGrace time.Duration
+	// an optional watcher of the webhook events, the transaction is fetched as soon as an event reports
+	// its final state, polling continues with MaxInterval in case an event is lost
+	Watcher *TransactionWatcher
+}
+
+// WaitForFinalState fetches the transaction with the ID id until it is completed, declined or failed
+// and returns it, the ReasonCode of the transaction tells why it was declined or failed.
+// It polls with an exponential backoff, or waits for the webhook events of opts.Watcher. opts may be nil.
+// Network errors, 429 and 5xx responses do not end the wait, nor does a 404 of a transaction not visible yet
+// until opts.NotFoundGrace has passed, the other errors are returned.
+// Use WaitForFinalStateWithContext to limit the wait, a scheduled payment stays pending until its date.
+func (p *PaymentService) WaitForFinalState(id string, opts *WaitOptions) (*TransactionResp, error) {
+	return p.WaitForFinalStateWithContext(context.Background(), id, opts)
+}
+
+// WaitForFinalStateWithContext: same as WaitForFinalState, the wait ends with the error of ctx once it is done.
+func (p *PaymentService) WaitForFinalStateWithContext(ctx context.Context, id string, opts *WaitOptions) (*TransactionResp, error) {
+	if opts == nil {
+		opts = &WaitOptions{}
+	}
+	interval, maxInterval := opts.MinInterval, opts.MaxInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if maxInterval <= 0 {
+		maxInterval = 30 * time.Second
+	}
+	grace := opts.NotFoundGrace
+	if grace <= 0 {
+		grace = 10 * time.Second
+	}
+	start := time.Now()
+
+	var final <-chan struct{}
+	if opts.Watcher != nil {
+		var cancel func()
+		final, cancel = opts.Watcher.subscribe(id)
+		defer cancel()
+		// the events are awaited, polling only recovers from a lost event
+		interval = maxInterval
+	}
+
+	for {
+		t, err := p.WithIdWithContext(ctx, id)
+		switch {
+		case err == nil && t.State.IsFinal():
+			return t, nil
+		case err != nil && ctx.Err() != nil:
+			return nil, ctx.Err()
+		case err != nil && !transient(err):
+			return nil, err
+		case IsNotFound(err) && time.Since(start) >= grace:
+			// the ID is wrong or the transaction is not visible to the client
+			return nil, err
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-final:
+			timer.Stop()
+			final = nil
+		case <-timer.C:
+		}
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// WaitForFinalState: same as PaymentService.WaitForFinalState for the transaction of a transfer.
+func (t *TransferService) WaitForFinalState(id string, opts *WaitOptions) (*TransactionResp, error) {
+	return t.WaitForFinalStateWithContext(context.Background(), id, opts)
+}
+
+// WaitForFinalStateWithContext: same as WaitForFinalState, the wait ends with the error of ctx once it is done.
+func (t *TransferService) WaitForFinalStateWithContext(ctx context.Context, id string, opts *WaitOptions) (*TransactionResp, error) {
+	p := &PaymentService{options: t.options}
+
+	return p.WaitForFinalStateWithContext(ctx, id, opts)
+}
+
+// transient reports whether err of fetching a transaction may go away by polling again.
+func transient(err error) bool {
+	var netErr *NetworkError
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+
+	return false
+}
+
+// TransactionWatcher notifies WaitForFinalState of the webhook events reporting the final state of a transaction.
+// It is an http.Handler to be used as the webhook endpoint, or the events received by another handler
+// can be passed to Notify:
+//
+//	watcher := business.NewTransactionWatcher()
+//	http.Handle("/revolut/webhook", watcher)
+//
+//	t, err := bC.Payment().WaitForFinalState(payment.Id, &business.WaitOptions{Watcher: watcher})
+type TransactionWatcher struct {
+	mu      sync.Mutex
+	waiters map[string][]chan struct{}
+}
+
+// NewTransactionWatcher creates a watcher without waiters.
+func NewTransactionWatcher() *TransactionWatcher {
+	return &TransactionWatcher{waiters: map[string][]chan struct{}{}}
+}
+
+// Notify wakes up the waiters of the transaction with the ID id when state is final.
+func (w *TransactionWatcher) Notify(id string, state PaymentState) {
+	if !state.IsFinal() {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, c := range w.waiters[id] {
+		select {
+		case c <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// ServeHTTP decodes a TransactionCreated or TransactionStateChanged event and notifies the waiters,
+// other events are acknowledged and ignored.
+func (w *TransactionWatcher) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var event struct {
+		Event string          `json:"event"`
+		Data  json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(b, &event); err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	switch event.Event {
+	case "TransactionStateChanged":
+		data := &TransactionStateChangedEventData{}
+		if err := json.Unmarshal(event.Data, data); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Notify(data.ID, PaymentState(data.NewState))
+	case "TransactionCreated":
+		data := &TransactionCreatedEventData{}
+		if err := json.Unmarshal(event.Data, data); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Notify(data.Id, data.State)
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// subscribe returns a channel receiving the final state notifications of the transaction id
+// and a function removing it.
+func (w *TransactionWatcher) subscribe(id string) (<-chan struct{}, func()) {
+	c := make(chan struct{}, 1)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.waiters == nil {
+		w.waiters = map[string][]chan struct{}{}
+	}
+	w.waiters[id] = append(w.waiters[id], c)
+
+	return c, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		waiters := w.waiters[id][:0]
+		for _, o := range w.waiters[id] {
+			if o != c {
+				waiters = append(waiters, o)
+			}
+		}
+		if len(waiters) == 0 {
+			delete(w.waiters, id)
+		} else {
+			w.waiters[id] = waiters
+		}
+	}
+}
@@ -2,20 +2,54 @@ package request
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 type Config struct {
+	// Context controls cancellation and deadline of the request, context.Background() is used when nil
+	Context     context.Context
 	Method      string
 	Url         string
 	AccessToken string
-	Sandbox     bool
 	Body        interface{}
 	ContentType ContentType
+	// the request can be safely sent again, e.g. a POST carrying a request_id
+	Idempotent bool
+	// an optional lookup of the resource created by an attempt whose outcome is unknown, i.e. which failed
+	// with a network error or a 5xx status. It returns the body of the resource, which is returned as the response,
+	// or nil when the attempt was not processed and the request may be sent again. When the lookup fails,
+	// the request is not sent again. A request with a Lookup is retried according to Options.Retry.
+	Lookup func(ctx context.Context) ([]byte, error)
+	// Options shared by all requests of a client, defaults are used when nil
+	Options *Options
+}
+
+// Options holds the transport settings of a client.
+type Options struct {
+	// the HTTP client used to send requests, http.DefaultClient when nil
+	HttpClient *http.Client
+	// an optional value of the User-Agent header
+	UserAgent string
+	// an optional base URL of the environment replacing the scheme and host of every request URL
+	BaseUrl string
+	// an optional policy for retrying failed requests, requests are not retried when nil
+	Retry *RetryPolicy
+	// an optional source of access tokens taking precedence over Config.AccessToken
+	TokenSource TokenSource
+}
+
+// TokenSource provides the access token of every request attempt.
+type TokenSource interface {
+	// Token returns a valid access token
+	Token(ctx context.Context) (string, error)
+	// Invalidate discards token after it has been rejected by the API
+	Invalidate(token string)
 }
 
 type ContentType string
@@ -25,11 +59,19 @@ const (
 	ContentType_APPLICATION_JSON ContentType = "application/json"
 )
 
+// New sends the request described by conf and returns the response body and status code,
+// a response with a non-2xx status is returned as *Error and a failure to get a response as *NetworkError.
+// Failed attempts are retried according to Options.Retry.
 func New(conf Config) ([]byte, int, error) {
 
 	var b []byte
 	var err error
 
+	opts := conf.Options
+	if opts == nil {
+		opts = &Options{}
+	}
+
 	switch conf.ContentType {
 	case ContentType_APPLICATION_FORM:
 		b = []byte(conf.Body.(url.Values).Encode())
@@ -41,28 +83,157 @@ func New(conf Config) ([]byte, int, error) {
 		}
 	}
 
-	if conf.Sandbox {
-		conf.Url = fmt.Sprintf("%ssandbox-%s", conf.Url[:8], conf.Url[8:])
+	if opts.BaseUrl != "" {
+		conf.Url, err = rebase(conf.Url, opts.BaseUrl)
+		if err != nil {
+			return []byte{}, 0, err
+		}
+	}
+
+	ctx := conf.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	maxAttempts := 1
+	if opts.Retry != nil && retryable(conf) {
+		maxAttempts = opts.Retry.MaxAttempts
+	}
+
+	reauthorized := false
+	for attempt := 1; ; attempt++ {
+		token := conf.AccessToken
+		if opts.TokenSource != nil {
+			token, err = opts.TokenSource.Token(ctx)
+			if err != nil {
+				return []byte{}, 0, err
+			}
+		}
+
+		resp, statusCode, header, err := send(ctx, conf, opts, token, b)
+		if err != nil {
+			found, resend := lookup(ctx, conf)
+			if found != nil {
+				return found, http.StatusOK, nil
+			}
+			if !resend || attempt >= maxAttempts || ctx.Err() != nil {
+				return []byte{}, 0, &NetworkError{Method: conf.Method, Url: conf.Url, Attempts: attempt, Err: err}
+			}
+			if err := sleep(ctx, opts.Retry.backoff(attempt)); err != nil {
+				return []byte{}, 0, &NetworkError{Method: conf.Method, Url: conf.Url, Attempts: attempt, Err: err}
+			}
+			continue
+		}
+
+		if statusCode >= 200 && statusCode <= 299 {
+			return resp, statusCode, nil
+		}
+
+		// the token may have been revoked or expired in the meantime, a rejected request is sent once more with a new one
+		if statusCode == http.StatusUnauthorized && opts.TokenSource != nil && !reauthorized {
+			opts.TokenSource.Invalidate(token)
+			reauthorized = true
+			maxAttempts++
+			continue
+		}
+
+		// the request may have been processed before the server failed
+		if statusCode >= 500 {
+			found, resend := lookup(ctx, conf)
+			if found != nil {
+				return found, http.StatusOK, nil
+			}
+			if !resend {
+				return resp, statusCode, newError(conf.Method, conf.Url, statusCode, resp, attempt)
+			}
+		}
+
+		if attempt >= maxAttempts || !retryableStatus(statusCode) {
+			return resp, statusCode, newError(conf.Method, conf.Url, statusCode, resp, attempt)
+		}
+
+		wait := opts.Retry.backoff(attempt)
+		if d, ok := retryAfter(header); ok {
+			wait = d
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return resp, statusCode, newError(conf.Method, conf.Url, statusCode, resp, attempt)
+		}
+	}
+}
+
+// lookup finds the outcome of an attempt which may have been processed with conf.Lookup. It returns the body
+// of the found resource, or whether the request may be sent again: always without a Lookup, never when
+// the outcome remains unknown because the lookup failed.
+func lookup(ctx context.Context, conf Config) ([]byte, bool) {
+	if conf.Lookup == nil {
+		return nil, true
 	}
+	if ctx.Err() != nil {
+		return nil, false
+	}
+
+	found, err := conf.Lookup(ctx)
+	if err != nil {
+		return nil, false
+	}
+
+	return found, found == nil
+}
 
-	req, err := http.NewRequest(conf.Method, conf.Url, bytes.NewReader(b))
+// send makes a single attempt of the request.
+func send(ctx context.Context, conf Config, opts *Options, token string, body []byte) ([]byte, int, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, conf.Method, conf.Url, bytes.NewReader(body))
 	if err != nil {
-		return []byte{}, 0, err
+		return nil, 0, nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", conf.AccessToken))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	if conf.ContentType != "" {
+		req.Header.Set("Content-Type", string(conf.ContentType))
+	}
+	if opts.UserAgent != "" {
+		req.Header.Set("User-Agent", opts.UserAgent)
+	}
 
-	c := &http.Client{}
+	c := opts.HttpClient
+	if c == nil {
+		c = http.DefaultClient
+	}
 
 	resp, err := c.Do(req)
 	if err != nil {
-		return []byte{}, 0, err
+		return nil, 0, nil, err
 	}
+	defer resp.Body.Close()
 
-	b, err = ioutil.ReadAll(resp.Body)
+	b, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return []byte{}, 0, err
+		return nil, 0, nil, err
+	}
+
+	return b, resp.StatusCode, resp.Header, nil
+}
+
+// rebase replaces the scheme and host of rawUrl with the ones of baseUrl,
+// a path of baseUrl is prepended to the path of rawUrl.
+func rebase(rawUrl, baseUrl string) (string, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", err
+	}
+
+	base, err := url.Parse(baseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = base.Scheme
+	u.Host = base.Host
+	u.Path = strings.TrimSuffix(base.Path, "/") + u.Path
+	if u.RawPath != "" {
+		u.RawPath = strings.TrimSuffix(base.EscapedPath(), "/") + u.RawPath
 	}
 
-	return b, resp.StatusCode, nil
+	return u.String(), nil
 }
@@ -0,0 +1,116 @@
+package request
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy describes how requests failing with a network error, 429 or 5xx status are retried.
+// Only GET requests and requests marked as idempotent are retried.
+type RetryPolicy struct {
+	// the maximum number of attempts including the first one, requests are not retried when lower than 2
+	MaxAttempts int
+	// the delay before the first retry, doubled with every further attempt
+	MinBackoff time.Duration
+	// the upper bound of the delay between two attempts
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy retries up to 3 times waiting 0.5s, 1s and 2s (each with jitter).
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  30 * time.Second,
+}
+
+var (
+	rndMu sync.Mutex
+	rnd   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// backoff returns a jittered delay before the attempt following attempt,
+// it is a random value between a half and the whole of the exponential backoff.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	rndMu.Lock()
+	jitter := time.Duration(rnd.Int63n(int64(d/2) + 1))
+	rndMu.Unlock()
+
+	return d/2 + jitter
+}
+
+// retryable reports whether a request with the given method may be sent again.
+func retryable(conf Config) bool {
+	return conf.Method == http.MethodGet || conf.Method == http.MethodHead || conf.Idempotent || conf.Lookup != nil
+}
+
+// retryableStatus reports whether a response with statusCode is worth retrying.
+func retryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// retryAfter parses the Retry-After header given either in seconds or as an HTTP date.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if s, err := strconv.Atoi(v); err == nil {
+		if s < 0 {
+			return 0, false
+		}
+		return time.Duration(s) * time.Second, true
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	d := time.Until(t)
+	if d < 0 {
+		d = 0
+	}
+
+	return d, true
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,58 @@
+package business
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/rysavyvladan/go-revolut/business/1.0/request"
+	"net/http"
+	"net/url"
+)
+
+// the largest request_id accepted by the API
+const maxRequestIdLength = 40
+
+// NewRequestId returns a random request ID of 32 hexadecimal characters.
+func NewRequestId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("business: reading random bytes: %v", err))
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// ensureRequestId sets a new request ID when *requestId is empty, the caller keeps it to look the transaction up.
+func ensureRequestId(requestId *string) {
+	if *requestId == "" {
+		*requestId = NewRequestId()
+	}
+}
+
+// validateRequestId fails when requestId is longer than the API accepts.
+func validateRequestId(field, requestId string) error {
+	if len(requestId) > maxRequestIdLength {
+		return &ValidationError{Field: field, Message: fmt.Sprintf("%q has more than %d characters", requestId, maxRequestIdLength)}
+	}
+
+	return nil
+}
+
+// lookupRequestId returns a request.Config Lookup finding the transaction created with requestId,
+// so that a request whose response was lost is not sent again when it was processed.
+func lookupRequestId(options *request.Options, requestId string) func(ctx context.Context) ([]byte, error) {
+	return func(ctx context.Context) ([]byte, error) {
+		resp, _, err := request.New(request.Config{
+			Context: ctx,
+			Options: options,
+			Method:  http.MethodGet,
+			Url:     fmt.Sprintf("https://b2b.revolut.com/api/1.0/transaction/%s?id_type=request_id", url.PathEscape(requestId)),
+		})
+		if IsNotFound(err) {
+			return nil, nil
+		}
+
+		return resp, err
+	}
+}
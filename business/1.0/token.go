@@ -0,0 +1,126 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const defaultRefreshMargin = time.Minute
+
+// tokenSource provides a valid access token to every request of a Client. It is safe for concurrent use,
+// the token is refreshed by a single goroutine while the others wait for the result.
+type tokenSource struct {
+	oa     *OAuthService
+	margin time.Duration
+	store  TokenStore
+	// sem guards the fields below, it is a channel rather than a mutex so waiting can be cancelled
+	sem          chan struct{}
+	refreshToken string
+	accessToken  string
+	expiration   time.Time
+	// the last token rejected by the API, a stored copy of it is not reused
+	invalidated string
+}
+
+func newTokenSource(oa *OAuthService, refreshToken string, margin time.Duration, store TokenStore) *tokenSource {
+	return &tokenSource{
+		oa:           oa,
+		margin:       margin,
+		store:        store,
+		sem:          make(chan struct{}, 1),
+		refreshToken: refreshToken,
+	}
+}
+
+// Token returns the current access token, a new one is requested when the current
+// one expires within the refresh margin.
+func (ts *tokenSource) Token(ctx context.Context) (string, error) {
+	select {
+	case ts.sem <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-ts.sem }()
+
+	if ts.valid(time.Now()) {
+		return ts.accessToken, nil
+	}
+
+	if ts.store != nil {
+		if l, ok := ts.store.(TokenStoreLocker); ok {
+			unlock, err := l.Lock(ctx)
+			if err != nil {
+				return "", err
+			}
+			defer unlock()
+		}
+
+		// another process or a previous run may have refreshed the token already
+		t, err := ts.store.Load()
+		if err != nil {
+			return "", err
+		}
+		if t != nil {
+			if t.RefreshToken != "" {
+				ts.refreshToken = t.RefreshToken
+			}
+			if t.AccessToken != ts.invalidated && t.Expiration.After(ts.expiration) {
+				ts.accessToken = t.AccessToken
+				ts.expiration = t.Expiration
+			}
+			if ts.valid(time.Now()) {
+				return ts.accessToken, nil
+			}
+		}
+	}
+
+	now := time.Now()
+	accessToken, err := ts.oa.RefreshAccessTokenWithContext(ctx, ts.refreshToken)
+	if err != nil {
+		// a token within the refresh margin is still good to use
+		if ts.accessToken != "" && now.Before(ts.expiration) {
+			return ts.accessToken, nil
+		}
+		return "", err
+	}
+
+	ts.accessToken = accessToken.AccessToken
+	ts.expiration = now.Add(time.Duration(accessToken.ExpiresIn) * time.Second)
+	if accessToken.RefreshToken != "" {
+		ts.refreshToken = accessToken.RefreshToken
+	}
+
+	if ts.store != nil {
+		if err := ts.store.Save(ts.token()); err != nil {
+			return "", fmt.Errorf("business: saving refreshed token: %w", err)
+		}
+	}
+
+	return ts.accessToken, nil
+}
+
+// Invalidate discards token so the next call of Token requests a new one, even when the TokenStore still holds it.
+func (ts *tokenSource) Invalidate(token string) {
+	ts.sem <- struct{}{}
+	defer func() { <-ts.sem }()
+
+	ts.invalidated = token
+	if ts.accessToken == token {
+		ts.accessToken = ""
+		ts.expiration = time.Time{}
+	}
+}
+
+// valid reports whether the access token can be used at now without a refresh.
+func (ts *tokenSource) valid(now time.Time) bool {
+	return ts.accessToken != "" && now.Add(ts.margin).Before(ts.expiration)
+}
+
+func (ts *tokenSource) token() *Token {
+	return &Token{
+		AccessToken:  ts.accessToken,
+		Expiration:   ts.expiration,
+		RefreshToken: ts.refreshToken,
+	}
+}
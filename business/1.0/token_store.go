@@ -0,0 +1,163 @@
+package business
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Token is the OAuth state of a Client persisted by a TokenStore.
+type Token struct {
+	// the access token
+	AccessToken string `json:"access_token"`
+	// the instant when the access token expires
+	Expiration time.Time `json:"expiration"`
+	// the refresh token, Revolut may rotate it on every refresh
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenStore persists the tokens of a Client, so they survive restarts and can be shared between processes.
+type TokenStore interface {
+	// Load returns the stored token, nil when nothing has been stored yet
+	Load() (*Token, error)
+	// Save replaces the stored token
+	Save(token *Token) error
+}
+
+// TokenStoreLocker is implemented by a TokenStore shared between processes,
+// the Client holds the lock while it loads, refreshes and saves the token.
+type TokenStoreLocker interface {
+	// Lock blocks until the lock is acquired or ctx is done
+	Lock(ctx context.Context) (unlock func(), err error)
+}
+
+// MemoryTokenStore keeps the token in memory, it can be shared by several clients of one process.
+type MemoryTokenStore struct {
+	mu    sync.Mutex
+	token *Token
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{}
+}
+
+func (s *MemoryTokenStore) Load() (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil {
+		return nil, nil
+	}
+	t := *s.token
+
+	return &t, nil
+}
+
+func (s *MemoryTokenStore) Save(token *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := *token
+	s.token = &t
+
+	return nil
+}
+
+const (
+	fileTokenStoreLockPoll  = 50 * time.Millisecond
+	fileTokenStoreLockStale = time.Minute
+)
+
+// FileTokenStore keeps the token in a JSON file readable only by its owner.
+// The file is replaced atomically and a lock file next to it serialises refreshes of several processes.
+type FileTokenStore struct {
+	path string
+}
+
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{
+		path: path,
+	}
+}
+
+func (s *FileTokenStore) Load() (*Token, error) {
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Token{}
+	if err := json.Unmarshal(b, t); err != nil {
+		return nil, fmt.Errorf("business: token file %s: %w", s.path, err)
+	}
+
+	return t, nil
+}
+
+func (s *FileTokenStore) Save(token *Token) error {
+	b, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path)
+}
+
+// Lock creates the lock file, a lock file older than a minute is considered abandoned and removed.
+func (s *FileTokenStore) Lock(ctx context.Context) (func(), error) {
+	lockPath := s.path + ".lock"
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if fi, err := os.Stat(lockPath); err == nil && time.Since(fi.ModTime()) > fileTokenStoreLockStale {
+			os.Remove(lockPath)
+			continue
+		}
+
+		t := time.NewTimer(fileTokenStoreLockPoll)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
@@ -1,18 +1,16 @@
 package business
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"github.com/rysavyvladan/go-revolut/business/1.0/request"
+	"github.com/rysavyvladan/go-revolut/money"
 	"net/http"
 	"time"
 )
 
 type TransferService struct {
-	accessToken string
-	sandbox     bool
-
-	err error
+	options *request.Options
 }
 
 type TransferReq struct {
@@ -24,9 +22,9 @@ type TransferReq struct {
 	// the ID of a target account
 	TargetAccountId string `json:"target_account_id"`
 	// the transaction amount
-	Amount float64 `json:"amount"`
+	Amount money.Decimal `json:"amount"`
 	// the transaction currency, both source and target accounts should be in this currency
-	Currency string `json:"currency"`
+	Currency money.Currency `json:"currency"`
 	// an optional textual reference shown on the transaction
 	Reference string `json:"reference,omitempty"`
 }
@@ -51,27 +49,45 @@ type TransferResp struct {
 	CompletedAt time.Time `json:"completed_at"`
 }
 
+// Validate checks the length of the request ID, the currency and the decimal places of the amount, it is called by Create.
+func (t *TransferReq) Validate() error {
+	if err := validateRequestId("TransferReq.RequestId", t.RequestId); err != nil {
+		return err
+	}
+	if err := validateCurrency("TransferReq.Currency", t.Currency); err != nil {
+		return err
+	}
+
+	return validateAmount("TransferReq.Amount", t.Amount, t.Currency)
+}
+
 // Create: This endpoint processes transfers between accounts of the business with the same currency.
+// An empty RequestId of transferReq is set to a new one. When the outcome of the request is unknown, e.g. after
+// a timeout, the transfer is looked up by its RequestId and only sent again when it was not created.
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#transfers-create-transfer
 func (t *TransferService) Create(transferReq *TransferReq) (*TransferResp, error) {
-	if t.err != nil {
-		return nil, t.err
+	return t.CreateWithContext(context.Background(), transferReq)
+}
+
+// CreateWithContext: same as Create, the request is bound to ctx for cancellation and deadline.
+func (t *TransferService) CreateWithContext(ctx context.Context, transferReq *TransferReq) (*TransferResp, error) {
+	ensureRequestId(&transferReq.RequestId)
+	if err := transferReq.Validate(); err != nil {
+		return nil, err
 	}
 
-	resp, statusCode, err := request.New(request.Config{
+	resp, _, err := request.New(request.Config{
+		Context:     ctx,
+		Options:     t.options,
 		Method:      http.MethodPost,
 		Url:         "https://b2b.revolut.com/api/1.0/transfer",
-		AccessToken: t.accessToken,
-		Sandbox:     t.sandbox,
 		Body:        transferReq,
 		ContentType: request.ContentType_APPLICATION_JSON,
+		Lookup:      lookupRequestId(t.options, transferReq.RequestId),
 	})
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
 
 	r := &TransferResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
@@ -1,17 +1,14 @@
 package business
 
 import (
-	"errors"
+	"context"
 	"github.com/rysavyvladan/go-revolut/business/1.0/request"
 	"net/http"
 	"time"
 )
 
 type WebhookService struct {
-	accessToken string
-	sandbox     bool
-
-	err error
+	options *request.Options
 }
 
 type TransactionStateChangedEvent struct {
@@ -56,7 +53,7 @@ type TransactionCreatedEventData struct {
 	// the instant when the transaction was completed, mandatory for completed state only
 	CompletedAt time.Time `json:"completed_at"`
 	// an optional date when the transaction was scheduled for
-	ScheduledFor string `json:"scheduled_for"`
+	ScheduledFor Date `json:"scheduled_for"`
 	// a user provided payment reference
 	Reference string `json:"reference"`
 	// the legs of transaction, there'll be 2 legs between your Revolut accounts and 1 leg in other cases
@@ -66,15 +63,16 @@ type TransactionCreatedEventData struct {
 // Set:
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#web-hooks-setting-up-a-web-hook
 func (p *WebhookService) Set(url string) error {
-	if p.err != nil {
-		return p.err
-	}
+	return p.SetWithContext(context.Background(), url)
+}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodPost,
-		Url:         "https://b2b.revolut.com/api/1.0/webhook",
-		AccessToken: p.accessToken,
-		Sandbox:     p.sandbox,
+// SetWithContext: same as Set, the request is bound to ctx for cancellation and deadline.
+func (p *WebhookService) SetWithContext(ctx context.Context, url string) error {
+	_, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: p.options,
+		Method:  http.MethodPost,
+		Url:     "https://b2b.revolut.com/api/1.0/webhook",
 		Body: struct {
 			// call back endpoint of the client system, https is the supported protocol
 			Url string `json:"url"`
@@ -84,9 +82,6 @@ func (p *WebhookService) Set(url string) error {
 	if err != nil {
 		return err
 	}
-	if statusCode != http.StatusNoContent {
-		return errors.New(string(resp))
-	}
 
 	return nil
 }
@@ -94,22 +89,20 @@ func (p *WebhookService) Set(url string) error {
 // Delete: Use this API request to delete a web-hook
 // doc: https://revolut-engineering.github.io/api-docs/business-api/#web-hooks-setting-up-a-web-hook
 func (p *WebhookService) Delete() error {
-	if p.err != nil {
-		return p.err
-	}
+	return p.DeleteWithContext(context.Background())
+}
 
-	resp, statusCode, err := request.New(request.Config{
-		Method:      http.MethodDelete,
-		Url:         "https://b2b.revolut.com/api/1.0/webhook",
-		AccessToken: p.accessToken,
-		Sandbox:     p.sandbox,
+// DeleteWithContext: same as Delete, the request is bound to ctx for cancellation and deadline.
+func (p *WebhookService) DeleteWithContext(ctx context.Context) error {
+	_, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: p.options,
+		Method:  http.MethodDelete,
+		Url:     "https://b2b.revolut.com/api/1.0/webhook",
 	})
 	if err != nil {
 		return err
 	}
-	if statusCode != http.StatusNoContent {
-		return errors.New(string(resp))
-	}
 
 	return nil
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	business "github.com/rysavyvladan/go-revolut/business/1.0"
+	"os"
+	"time"
+)
+
+func authorise(args []string) error {
+	fs := flag.NewFlagSet("authorise", flag.ExitOnError)
+	clientId := fs.String("client-id", "", "the client ID of the API certificate")
+	issuer := fs.String("issuer", "", "the issuer of the client assertion, the domain of the redirect URI")
+	keyFile := fs.String("key", "privatekey.pem", "the PEM file with the private key of the API certificate")
+	redirectUri := fs.String("redirect-uri", "http://127.0.0.1:8080/", "the redirect URI of the API certificate")
+	listen := fs.String("listen", "", "an optional address to listen on instead of the host and port of the redirect URI")
+	sandbox := fs.Bool("sandbox", false, "use the sandbox environment")
+	tokenFile := fs.String("token-file", "", "an optional file to store the tokens in, usable with business.NewFileTokenStore")
+	timeout := fs.Duration("timeout", 5*time.Minute, "how long to wait for the authorisation")
+	fs.Parse(args)
+
+	if *clientId == "" || *issuer == "" {
+		return errors.New("-client-id and -issuer are required")
+	}
+
+	privateKey, err := business.LoadPrivateKeyFile(*keyFile)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	oa := business.NewOAuth(*clientId, privateKey, *issuer, *sandbox)
+	issuedAt := time.Now()
+	resp, err := oa.Authorise(ctx, business.ConsentFlow{
+		RedirectUri: *redirectUri,
+		ListenAddr:  *listen,
+		OnAuthorisationURL: func(authorisationUrl string) {
+			fmt.Fprintf(os.Stderr, "Open the following URL in a browser and authorise the app:\n\n\t%s\n\n", authorisationUrl)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if *tokenFile != "" {
+		err := business.NewFileTokenStore(*tokenFile).Save(&business.Token{
+			AccessToken:  resp.AccessToken,
+			Expiration:   issuedAt.Add(time.Duration(resp.ExpiresIn) * time.Second),
+			RefreshToken: resp.RefreshToken,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Tokens saved to %s\n", *tokenFile)
+	}
+
+	fmt.Printf("access_token:  %s\nexpires_in:    %d\nrefresh_token: %s\n", resp.AccessToken, resp.ExpiresIn, resp.RefreshToken)
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	business "github.com/rysavyvladan/go-revolut/business/1.0"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// clientFlags are the flags of the commands calling the Business API with the tokens saved by authorise.
+type clientFlags struct {
+	clientId  *string
+	issuer    *string
+	keyFile   *string
+	tokenFile *string
+	sandbox   *bool
+	baseUrl   *string
+}
+
+func newClientFlags(fs *flag.FlagSet) *clientFlags {
+	return &clientFlags{
+		clientId:  fs.String("client-id", "", "the client ID of the API certificate"),
+		issuer:    fs.String("issuer", "", "the issuer of the client assertion, the domain of the redirect URI"),
+		keyFile:   fs.String("key", "privatekey.pem", "the PEM file with the private key of the API certificate"),
+		tokenFile: fs.String("token-file", "revolut-token.json", "the file with the tokens saved by authorise"),
+		sandbox:   fs.Bool("sandbox", false, "use the sandbox environment"),
+		baseUrl:   fs.String("base-url", "", "an optional base URL of the API instead of the production or sandbox one"),
+	}
+}
+
+func (f *clientFlags) client() (*business.Client, error) {
+	if *f.clientId == "" || *f.issuer == "" {
+		return nil, errors.New("-client-id and -issuer are required")
+	}
+
+	privateKey, err := business.LoadPrivateKeyFile(*f.keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []business.Option{business.WithTokenStore(business.NewFileTokenStore(*f.tokenFile))}
+	if *f.baseUrl != "" {
+		opts = append(opts, business.WithBaseURL(*f.baseUrl))
+	}
+
+	return business.NewClient(*f.clientId, "", privateKey, *f.issuer, *f.sandbox, opts...)
+}
+
+// interruptContext returns a context cancelled by an interrupt, e.g. Ctrl+C, or a termination signal.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(interrupt)
+	}()
+
+	return ctx, cancel
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	business "github.com/rysavyvladan/go-revolut/business/1.0"
+	"os"
+	"strings"
+)
+
+const counterpartiesUsage = `Usage:
+
+	go-revolut counterparties <command> [arguments]
+
+Commands:
+
+	export    write all counterparties to a JSON or CSV file
+	import    create the counterparties of a CSV which do not exist yet
+	sync      plan and apply the changes making the counterparties match a CSV
+
+Run "go-revolut counterparties <command> -h" for the arguments of a command.
+`
+
+func counterparties(args []string) error {
+	if len(args) < 1 {
+		fmt.Fprint(os.Stderr, counterpartiesUsage)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "export":
+		return exportCounterparties(args[1:])
+	case "import":
+		return importCounterparties(args[1:])
+	case "sync":
+		return syncCounterparties(args[1:])
+	case "-h", "-help", "--help", "help":
+		fmt.Print(counterpartiesUsage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], counterpartiesUsage)
+	}
+}
+
+func importCounterparties(args []string) error {
+	fs := flag.NewFlagSet("counterparties import", flag.ExitOnError)
+	cf := newClientFlags(fs)
+	columns := fs.String("columns", "", "an optional mapping of the fields to the column headers, e.g. company_name=Supplier,iban=IBAN")
+	comma := fs.String("comma", "", "the field separator, detected from the header line when empty")
+	dryRun := fs.Bool("dry-run", false, "only report which counterparties would be created")
+	concurrency := fs.Int("concurrency", 4, "the number of counterparties created at the same time")
+	reportFile := fs.String("report", "", "an optional file to write the CSV report to instead of the standard output")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-revolut counterparties import [flags] <file.csv>\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	opts, err := importOptions(*columns, *comma)
+	if err != nil {
+		return err
+	}
+	opts.DryRun = *dryRun
+	opts.Concurrency = *concurrency
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	bC, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := interruptContext()
+	defer cancel()
+
+	rows, err := bC.Counterparty().ImportWithContext(ctx, f, opts)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if *reportFile != "" {
+		if out, err = os.Create(*reportFile); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	if err := business.WriteImportReport(out, rows); err != nil {
+		return err
+	}
+
+	counts := map[business.ImportRowStatus]int{}
+	for _, row := range rows {
+		counts[row.Status]++
+	}
+	fmt.Fprintf(os.Stderr, "%d rows: %d created, %d to create, %d existing, %d duplicate, %d invalid, %d failed\n", len(rows),
+		counts[business.ImportRowStatus_CREATED], counts[business.ImportRowStatus_CREATE], counts[business.ImportRowStatus_EXISTING],
+		counts[business.ImportRowStatus_DUPLICATE], counts[business.ImportRowStatus_INVALID], counts[business.ImportRowStatus_FAILED])
+	if counts[business.ImportRowStatus_INVALID]+counts[business.ImportRowStatus_FAILED] > 0 {
+		return errors.New("some rows were not imported, see the report")
+	}
+
+	return nil
+}
+
+// importOptions parses the -columns and -comma flags of import and sync.
+func importOptions(columns, comma string) (*business.CounterpartyImport, error) {
+	opts := &business.CounterpartyImport{Columns: map[business.ImportField]string{}}
+	if columns != "" {
+		for _, m := range strings.Split(columns, ",") {
+			kv := strings.SplitN(m, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid column mapping %q, expected field=header", m)
+			}
+			opts.Columns[business.ImportField(strings.TrimSpace(kv[0]))] = kv[1]
+		}
+	}
+	switch comma {
+	case "":
+	case `\t`, "tab":
+		opts.Comma = '\t'
+	default:
+		if len(comma) != 1 {
+			return nil, errors.New("-comma must be a single character")
+		}
+		opts.Comma = rune(comma[0])
+	}
+
+	return opts, nil
+}
+
+func exportCounterparties(args []string) error {
+	fs := flag.NewFlagSet("counterparties export", flag.ExitOnError)
+	cf := newClientFlags(fs)
+	format := fs.String("format", "json", "the format of the file, json or csv")
+	output := fs.String("o", "", "an optional file to write to instead of the standard output")
+	fs.Parse(args)
+
+	bC, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := interruptContext()
+	defer cancel()
+
+	out := os.Stdout
+	if *output != "" {
+		if out, err = os.Create(*output); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+
+	return bC.Counterparty().ExportWithContext(ctx, out, business.ExportFormat(*format))
+}
+
+func syncCounterparties(args []string) error {
+	fs := flag.NewFlagSet("counterparties sync", flag.ExitOnError)
+	cf := newClientFlags(fs)
+	columns := fs.String("columns", "", "an optional mapping of the fields to the column headers, e.g. company_name=Supplier,iban=IBAN")
+	comma := fs.String("comma", "", "the field separator, detected from the header line when empty")
+	concurrency := fs.Int("concurrency", 4, "the number of counterparties created at the same time")
+	apply := fs.Bool("apply", false, "apply the plan, otherwise it is only printed")
+	confirmDeletes := fs.Bool("confirm-deletes", false, "allow the plan to delete the counterparties which are not in the file")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-revolut counterparties sync [flags] <file.csv>\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	opts, err := importOptions(*columns, *comma)
+	if err != nil {
+		return err
+	}
+	opts.Concurrency = *concurrency
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	bC, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := interruptContext()
+	defer cancel()
+
+	plan, err := bC.Counterparty().PlanSyncWithContext(ctx, f, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Println(plan)
+	if !*apply || plan.IsEmpty() {
+		return nil
+	}
+
+	err = bC.Counterparty().ApplySyncWithContext(ctx, plan, *confirmDeletes)
+	if errors.Is(err, business.ErrDeletesNotConfirmed) {
+		return errors.New("the plan deletes counterparties, run again with -confirm-deletes to apply it")
+	}
+	for _, row := range plan.Creates() {
+		if row.Err != nil {
+			fmt.Fprintf(os.Stderr, "line %d: %v\n", row.Line, row.Err)
+		}
+	}
+	for _, d := range plan.Deletes {
+		if d.Err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", d.Counterparty.Id, d.Err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("Applied.")
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"crypto/x509/pkix"
+	"flag"
+	"fmt"
+	business "github.com/rysavyvladan/go-revolut/business/1.0"
+	"os"
+	"time"
+)
+
+func keygen(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	keyFile := fs.String("key", "privatekey.pem", "the file to write the private key to")
+	certFile := fs.String("cert", "publiccert.cer", "the file to write the certificate to")
+	bits := fs.Int("bits", 2048, "the size of the RSA key in bits")
+	days := fs.Int("days", 1825, "the validity of the certificate in days")
+	commonName := fs.String("cn", "", "the common name of the certificate subject, e.g. the domain of the redirect URI")
+	organization := fs.String("o", "", "an optional organization of the certificate subject")
+	country := fs.String("c", "", "an optional country code of the certificate subject")
+	force := fs.Bool("force", false, "overwrite existing files")
+	fs.Parse(args)
+
+	if !*force {
+		for _, name := range []string{*keyFile, *certFile} {
+			if _, err := os.Stat(name); err == nil {
+				return fmt.Errorf("%s already exists, use -force to overwrite it", name)
+			}
+		}
+	}
+
+	subject := pkix.Name{CommonName: *commonName}
+	if *organization != "" {
+		subject.Organization = []string{*organization}
+	}
+	if *country != "" {
+		subject.Country = []string{*country}
+	}
+
+	keyPair, err := business.GenerateKeyPair(&business.KeyPairReq{
+		Bits:     *bits,
+		Subject:  subject,
+		Validity: time.Duration(*days) * 24 * time.Hour,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writeFile(*keyFile, keyPair.PrivateKeyPEM, 0600, *force); err != nil {
+		return err
+	}
+	if err := writeFile(*certFile, keyPair.CertificatePEM, 0644, *force); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Private key written to %s, keep it secret.\n", *keyFile)
+	fmt.Fprintf(os.Stderr, "Certificate written to %s, valid until %s. Upload it in the API settings of Revolut Business:\n\n",
+		*certFile, keyPair.Certificate.NotAfter.Format("2006-01-02"))
+	fmt.Print(string(keyPair.CertificatePEM))
+
+	return nil
+}
+
+// writeFile writes data to a new file, an existing file is replaced only when force is set.
+func writeFile(name string, data []byte, perm os.FileMode, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(name, flags, perm)
+	if os.IsExist(err) {
+		return fmt.Errorf("%s already exists, use -force to overwrite it", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
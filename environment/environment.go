@@ -0,0 +1,57 @@
+// Package environment selects the Revolut environment the business and merchant clients send requests to.
+package environment
+
+import "strings"
+
+// Environment is the production, the sandbox or a custom environment, e.g. a local fake server.
+type Environment struct {
+	sandbox bool
+	baseUrl string
+}
+
+var (
+	// Production is the live environment, e.g. https://b2b.revolut.com
+	Production = Environment{}
+	// Sandbox is the test environment, e.g. https://sandbox-b2b.revolut.com
+	Sandbox = Environment{sandbox: true}
+)
+
+// Custom sends the requests of every API to baseUrl, e.g. to a local fake server or a proxy.
+func Custom(baseUrl string) Environment {
+	return Environment{
+		baseUrl: strings.TrimSuffix(baseUrl, "/"),
+	}
+}
+
+// Url returns the base URL of an API in the environment, host is the production host of the API, e.g. b2b.revolut.com.
+func (e Environment) Url(host string) string {
+	switch {
+	case e.baseUrl != "":
+		return e.baseUrl
+	case e.sandbox:
+		return "https://sandbox-" + host
+	default:
+		return "https://" + host
+	}
+}
+
+// IsSandbox reports whether e is the sandbox environment.
+func (e Environment) IsSandbox() bool {
+	return e.sandbox
+}
+
+// IsCustom reports whether e is a custom environment.
+func (e Environment) IsCustom() bool {
+	return e.baseUrl != ""
+}
+
+func (e Environment) String() string {
+	switch {
+	case e.baseUrl != "":
+		return e.baseUrl
+	case e.sandbox:
+		return "sandbox"
+	default:
+		return "production"
+	}
+}
@@ -1,5 +1,3 @@
 module github.com/rysavyvladan/go-revolut
 
 go 1.14
-
-require github.com/dgrijalva/jwt-go v3.2.0+incompatible
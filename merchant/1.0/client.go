@@ -1,23 +1,29 @@
 package merchant
 
+import "github.com/rysavyvladan/go-revolut/merchant/1.0/request"
+
 type Client struct {
-	apiKey string
+	apiKey  string
+	options *request.Options
 }
 
-func NewClient(apiKey string) *Client {
+func NewClient(apiKey string, opts ...Option) *Client {
 	return &Client{
-		apiKey: apiKey,
+		apiKey:  apiKey,
+		options: newOptions(opts).request(),
 	}
 }
 
 func (m *Client) Order() *OrderService {
 	return &OrderService{
-		apiKey: m.apiKey,
+		apiKey:  m.apiKey,
+		options: m.options,
 	}
 }
 
 func (m *Client) Webhook() *WebhookService {
 	return &WebhookService{
-		apiKey: m.apiKey,
+		apiKey:  m.apiKey,
+		options: m.options,
 	}
 }
@@ -1,15 +1,17 @@
 package merchant
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/rysavyvladan/go-revolut/merchant/1.0/request"
+	"github.com/rysavyvladan/go-revolut/money"
 	"net/http"
 )
 
 type OrderService struct {
-	apiKey string
+	apiKey  string
+	options *request.Options
 }
 
 type OrderType string
@@ -79,8 +81,18 @@ const (
 )
 
 type Amount struct {
-	Value    int    `json:"value"`
-	Currency string `json:"currency"`
+	Value    int            `json:"value"`
+	Currency money.Currency `json:"currency"`
+}
+
+// Decimal returns the amount in major units, e.g. the Value 1050 in GBP as 10.50.
+func (a Amount) Decimal() money.Decimal {
+	return money.FromMinor(int64(a.Value), a.Currency)
+}
+
+// String formats the amount for display, e.g. "10.50 GBP".
+func (a Amount) String() string {
+	return money.Format(a.Decimal(), a.Currency)
 }
 
 type FeeType string
@@ -94,7 +106,7 @@ type Fee struct {
 	// Fee amount
 	Value int `json:"value"`
 	// Fee currency
-	Currency string `json:"currency"`
+	Currency money.Currency `json:"currency"`
 	// Fee type
 	Type FeeType `json:"type"`
 }
@@ -240,9 +252,9 @@ type OrderReq struct {
 	// Order description
 	Description string `json:"description"`
 	// Currency code
-	Currency string `json:"currency"`
+	Currency money.Currency `json:"currency"`
 	// Settlement currency. If it is equal to null then the payment is settled in transaction currency.
-	SettlementCurrency string `json:"settlement_currency"`
+	SettlementCurrency money.Currency `json:"settlement_currency"`
 	// Merchant customer ID
 	MerchantCustomerID string `json:"merchant_customer_id"`
 }
@@ -255,7 +267,7 @@ type RefundReq struct {
 	// Order description
 	Description string `json:"description"`
 	// Currency code
-	Currency string `json:"currency"`
+	Currency money.Currency `json:"currency"`
 }
 
 type RefundResp struct {
@@ -279,10 +291,33 @@ type RefundResp struct {
 	Related []AttemptRelated `json:"related"`
 }
 
+// Validate checks the currencies of the order, it is called by Create.
+func (o *OrderReq) Validate() error {
+	if err := validateCurrency("OrderReq.Currency", o.Currency); err != nil {
+		return err
+	}
+	if o.SettlementCurrency != "" {
+		return validateCurrency("OrderReq.SettlementCurrency", o.SettlementCurrency)
+	}
+
+	return nil
+}
+
 // Create:
 // doc: https://revolut-engineering.github.io/api-docs/merchant-api/#backend-api-backend-api-order-object-create-payment-order
 func (a *OrderService) Create(orderReq *OrderReq) (*OrderResp, error) {
-	resp, statusCode, err := request.New(request.Config{
+	return a.CreateWithContext(context.Background(), orderReq)
+}
+
+// CreateWithContext: same as Create, the request is bound to ctx for cancellation and deadline.
+func (a *OrderService) CreateWithContext(ctx context.Context, orderReq *OrderReq) (*OrderResp, error) {
+	if err := orderReq.Validate(); err != nil {
+		return nil, err
+	}
+
+	resp, _, err := request.New(request.Config{
+		Context:     ctx,
+		Options:     a.options,
 		Method:      http.MethodPost,
 		Url:         "https://merchant.revolut.com/api/1.0/orders",
 		ApiKey:      a.apiKey,
@@ -293,11 +328,7 @@ func (a *OrderService) Create(orderReq *OrderReq) (*OrderResp, error) {
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
-
-	var r *OrderResp
+	r := &OrderResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
 		return nil, err
 	}
@@ -308,20 +339,23 @@ func (a *OrderService) Create(orderReq *OrderReq) (*OrderResp, error) {
 // WithId: If you would like to get information about the created order, please use the following request.
 // doc: https://revolut-engineering.github.io/api-docs/merchant-api/#backend-api-backend-api-order-object-retrieve-order
 func (a *OrderService) WithId(id string) (*OrderResp, error) {
-	resp, statusCode, err := request.New(request.Config{
-		Method: http.MethodGet,
-		Url:    fmt.Sprintf("https://merchant.revolut.com/api/1.0/orders/%s", id),
-		ApiKey: a.apiKey,
+	return a.WithIdWithContext(context.Background(), id)
+}
+
+// WithIdWithContext: same as WithId, the request is bound to ctx for cancellation and deadline.
+func (a *OrderService) WithIdWithContext(ctx context.Context, id string) (*OrderResp, error) {
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: a.options,
+		Method:  http.MethodGet,
+		Url:     fmt.Sprintf("https://merchant.revolut.com/api/1.0/orders/%s", id),
+		ApiKey:  a.apiKey,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
-
-	var r *OrderResp
+	r := &OrderResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
 		return nil, err
 	}
@@ -333,20 +367,23 @@ func (a *OrderService) WithId(id string) (*OrderResp, error) {
 // capture it in order for it to be sent into the processing stage.
 // doc: https://revolut-engineering.github.io/api-docs/merchant-api/#backend-api-backend-api-order-object-capture-order
 func (a *OrderService) Capture(id string) (*OrderResp, error) {
-	resp, statusCode, err := request.New(request.Config{
-		Method: http.MethodPost,
-		Url:    fmt.Sprintf("https://merchant.revolut.com/api/1.0/orders/%s/capture", id),
-		ApiKey: a.apiKey,
+	return a.CaptureWithContext(context.Background(), id)
+}
+
+// CaptureWithContext: same as Capture, the request is bound to ctx for cancellation and deadline.
+func (a *OrderService) CaptureWithContext(ctx context.Context, id string) (*OrderResp, error) {
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: a.options,
+		Method:  http.MethodPost,
+		Url:     fmt.Sprintf("https://merchant.revolut.com/api/1.0/orders/%s/capture", id),
+		ApiKey:  a.apiKey,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
-
-	var r *OrderResp
+	r := &OrderResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
 		return nil, err
 	}
@@ -358,20 +395,23 @@ func (a *OrderService) Capture(id string) (*OrderResp, error) {
 // to not proceed with the order, the order can be cancelled manually.
 // doc: https://revolut-engineering.github.io/api-docs/merchant-api/#backend-api-backend-api-order-object-cancel-order
 func (a *OrderService) Cancel(id string) (*OrderResp, error) {
-	resp, statusCode, err := request.New(request.Config{
-		Method: http.MethodPost,
-		Url:    fmt.Sprintf("https://merchant.revolut.com/api/1.0/orders/%s/cancel", id),
-		ApiKey: a.apiKey,
+	return a.CancelWithContext(context.Background(), id)
+}
+
+// CancelWithContext: same as Cancel, the request is bound to ctx for cancellation and deadline.
+func (a *OrderService) CancelWithContext(ctx context.Context, id string) (*OrderResp, error) {
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: a.options,
+		Method:  http.MethodPost,
+		Url:     fmt.Sprintf("https://merchant.revolut.com/api/1.0/orders/%s/cancel", id),
+		ApiKey:  a.apiKey,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
-
-	var r *OrderResp
+	r := &OrderResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
 		return nil, err
 	}
@@ -379,11 +419,27 @@ func (a *OrderService) Cancel(id string) (*OrderResp, error) {
 	return r, nil
 }
 
+// Validate checks the currency of the refund, it is called by Refund.
+func (r *RefundReq) Validate() error {
+	return validateCurrency("RefundReq.Currency", r.Currency)
+}
+
 // Refund: In case the customer requires a refund for a payment that has been already captured,
 // the merchant can always issue a full or partial refund for a particular payment.
 // doc: https://revolut-engineering.github.io/api-docs/merchant-api/#backend-api-backend-api-order-object-refund-order
 func (a *OrderService) Refund(id string, refundReq *RefundReq) (*RefundResp, error) {
-	resp, statusCode, err := request.New(request.Config{
+	return a.RefundWithContext(context.Background(), id, refundReq)
+}
+
+// RefundWithContext: same as Refund, the request is bound to ctx for cancellation and deadline.
+func (a *OrderService) RefundWithContext(ctx context.Context, id string, refundReq *RefundReq) (*RefundResp, error) {
+	if err := refundReq.Validate(); err != nil {
+		return nil, err
+	}
+
+	resp, _, err := request.New(request.Config{
+		Context:     ctx,
+		Options:     a.options,
 		Method:      http.MethodPost,
 		Url:         fmt.Sprintf("https://merchant.revolut.com/api/1.0/orders/%s/refund", id),
 		ApiKey:      a.apiKey,
@@ -394,11 +450,7 @@ func (a *OrderService) Refund(id string, refundReq *RefundReq) (*RefundResp, err
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
-
-	var r *RefundResp
+	r := &RefundResp{}
 	if err := json.Unmarshal(resp, r); err != nil {
 		return nil, err
 	}
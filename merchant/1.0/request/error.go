@@ -0,0 +1,130 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNotFound matches an Error with status 404
+	ErrNotFound = errors.New("revolut: not found")
+	// ErrUnauthorized matches an Error with status 401
+	ErrUnauthorized = errors.New("revolut: unauthorized")
+	// ErrRateLimited matches an Error with status 429
+	ErrRateLimited = errors.New("revolut: rate limited")
+	// ErrValidation matches an Error with status 400 or 422
+	ErrValidation = errors.New("revolut: validation failed")
+)
+
+// Error is returned by New for every response with a non-2xx status.
+type Error struct {
+	// the HTTP status code of the response
+	StatusCode int
+	// the Revolut error code, empty when the body does not contain one
+	Code string
+	// the error message returned by Revolut, empty when the body does not contain one
+	Message string
+	// the HTTP method of the request
+	Method string
+	// the URL of the request
+	Url string
+	// the raw response body
+	Body []byte
+	// the number of attempts made
+	Attempts int
+}
+
+func newError(method, url string, statusCode int, body []byte, attempts int) *Error {
+	e := &Error{
+		StatusCode: statusCode,
+		Method:     method,
+		Url:        url,
+		Body:       body,
+		Attempts:   attempts,
+	}
+
+	var b struct {
+		Code             json.RawMessage `json:"code"`
+		Message          string          `json:"message"`
+		Error            string          `json:"error"`
+		ErrorDescription string          `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &b); err != nil {
+		return e
+	}
+
+	e.Code = strings.Trim(string(b.Code), `"`)
+	e.Message = b.Message
+	// the OAuth endpoints use the RFC 6749 error format
+	if e.Code == "" {
+		e.Code = b.Error
+	}
+	if e.Message == "" {
+		e.Message = b.ErrorDescription
+	}
+
+	return e
+}
+
+func (e *Error) Error() string {
+	s := fmt.Sprintf("revolut: %s %s: %d %s", e.Method, e.Url, e.StatusCode, http.StatusText(e.StatusCode))
+
+	msg := e.Message
+	if msg == "" {
+		msg = strings.TrimSpace(string(e.Body))
+	}
+	if msg != "" {
+		s += ": " + msg
+	}
+	if e.Code != "" {
+		s += fmt.Sprintf(" (code %s)", e.Code)
+	}
+	if e.Attempts > 1 {
+		s += fmt.Sprintf(" (after %d attempts)", e.Attempts)
+	}
+
+	return s
+}
+
+// Is reports whether the status of e corresponds to one of the sentinel errors.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	}
+
+	return false
+}
+
+// NetworkError is returned by New when the request could not be sent or the response could not be read.
+type NetworkError struct {
+	// the HTTP method of the request
+	Method string
+	// the URL of the request
+	Url string
+	// the number of attempts made
+	Attempts int
+	// the last underlying error
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	if e.Attempts > 1 {
+		return fmt.Sprintf("%v (after %d attempts)", e.Err, e.Attempts)
+	}
+
+	return e.Err.Error()
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
@@ -2,18 +2,39 @@ package request
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
 type Config struct {
+	// Context controls cancellation and deadline of the request, context.Background() is used when nil
+	Context     context.Context
 	Method      string
 	Url         string
 	ApiKey      string
 	Body        interface{}
 	ContentType ContentType
+	// the request can be safely sent again, e.g. a POST carrying a request_id
+	Idempotent bool
+	// Options shared by all requests of a client, defaults are used when nil
+	Options *Options
+}
+
+// Options holds the transport settings of a client.
+type Options struct {
+	// the HTTP client used to send requests, http.DefaultClient when nil
+	HttpClient *http.Client
+	// an optional value of the User-Agent header
+	UserAgent string
+	// an optional base URL of the environment replacing the scheme and host of every request URL
+	BaseUrl string
+	// an optional policy for retrying failed requests, requests are not retried when nil
+	Retry *RetryPolicy
 }
 
 type ContentType string
@@ -22,11 +43,19 @@ const (
 	ContentType_APPLICATION_JSON ContentType = "application/json"
 )
 
+// New sends the request described by conf and returns the response body and status code,
+// a response with a non-2xx status is returned as *Error and a failure to get a response as *NetworkError.
+// Failed attempts are retried according to Options.Retry.
 func New(conf Config) ([]byte, int, error) {
 
 	var b []byte
 	var err error
 
+	opts := conf.Options
+	if opts == nil {
+		opts = &Options{}
+	}
+
 	switch conf.ContentType {
 	case ContentType_APPLICATION_JSON:
 		b, err = json.Marshal(conf.Body)
@@ -34,24 +63,107 @@ func New(conf Config) ([]byte, int, error) {
 			return []byte{}, 0, err
 		}
 	}
-	req, err := http.NewRequest(conf.Method, conf.Url, bytes.NewReader(b))
+
+	if opts.BaseUrl != "" {
+		conf.Url, err = rebase(conf.Url, opts.BaseUrl)
+		if err != nil {
+			return []byte{}, 0, err
+		}
+	}
+
+	ctx := conf.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	maxAttempts := 1
+	if opts.Retry != nil && retryable(conf) {
+		maxAttempts = opts.Retry.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, statusCode, header, err := send(ctx, conf, opts, b)
+		if err != nil {
+			if attempt >= maxAttempts || ctx.Err() != nil {
+				return []byte{}, 0, &NetworkError{Method: conf.Method, Url: conf.Url, Attempts: attempt, Err: err}
+			}
+			if err := sleep(ctx, opts.Retry.backoff(attempt)); err != nil {
+				return []byte{}, 0, &NetworkError{Method: conf.Method, Url: conf.Url, Attempts: attempt, Err: err}
+			}
+			continue
+		}
+
+		if statusCode >= 200 && statusCode <= 299 {
+			return resp, statusCode, nil
+		}
+
+		if attempt >= maxAttempts || !retryableStatus(statusCode) {
+			return resp, statusCode, newError(conf.Method, conf.Url, statusCode, resp, attempt)
+		}
+
+		wait := opts.Retry.backoff(attempt)
+		if d, ok := retryAfter(header); ok {
+			wait = d
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return resp, statusCode, newError(conf.Method, conf.Url, statusCode, resp, attempt)
+		}
+	}
+}
+
+// send makes a single attempt of the request.
+func send(ctx context.Context, conf Config, opts *Options, body []byte) ([]byte, int, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, conf.Method, conf.Url, bytes.NewReader(body))
 	if err != nil {
-		return []byte{}, 0, err
+		return nil, 0, nil, err
 	}
 
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", conf.ApiKey))
+	if conf.ContentType != "" {
+		req.Header.Set("Content-Type", string(conf.ContentType))
+	}
+	if opts.UserAgent != "" {
+		req.Header.Set("User-Agent", opts.UserAgent)
+	}
 
-	c := &http.Client{}
+	c := opts.HttpClient
+	if c == nil {
+		c = http.DefaultClient
+	}
 
 	resp, err := c.Do(req)
 	if err != nil {
-		return []byte{}, 0, err
+		return nil, 0, nil, err
 	}
+	defer resp.Body.Close()
 
-	b, err = ioutil.ReadAll(resp.Body)
+	b, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return []byte{}, 0, err
+		return nil, 0, nil, err
+	}
+
+	return b, resp.StatusCode, resp.Header, nil
+}
+
+// rebase replaces the scheme and host of rawUrl with the ones of baseUrl,
+// a path of baseUrl is prepended to the path of rawUrl.
+func rebase(rawUrl, baseUrl string) (string, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", err
+	}
+
+	base, err := url.Parse(baseUrl)
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = base.Scheme
+	u.Host = base.Host
+	u.Path = strings.TrimSuffix(base.Path, "/") + u.Path
+	if u.RawPath != "" {
+		u.RawPath = strings.TrimSuffix(base.EscapedPath(), "/") + u.RawPath
 	}
 
-	return b, resp.StatusCode, nil
+	return u.String(), nil
 }
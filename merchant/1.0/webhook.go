@@ -1,14 +1,15 @@
 package merchant
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"github.com/rysavyvladan/go-revolut/merchant/1.0/request"
 	"net/http"
 )
 
 type WebhookService struct {
-	apiKey string
+	apiKey  string
+	options *request.Options
 }
 
 type WebhookUrl struct {
@@ -24,8 +25,15 @@ type WebhookResp struct {
 // Set:
 // doc: https://revolut-engineering.github.io/api-docs/merchant-api/#backend-api-backend-api-webhooks-set-or-revoke-webhook-url
 func (w *WebhookService) Set(webhookReq *WebhookUrl) error {
+	return w.SetWithContext(context.Background(), webhookReq)
+}
+
+// SetWithContext: same as Set, the request is bound to ctx for cancellation and deadline.
+func (w *WebhookService) SetWithContext(ctx context.Context, webhookReq *WebhookUrl) error {
 
-	resp, statusCode, err := request.New(request.Config{
+	_, _, err := request.New(request.Config{
+		Context:     ctx,
+		Options:     w.options,
 		Method:      http.MethodPost,
 		Url:         "https://merchant.revolut.com/api/1.0/webhooks",
 		ApiKey:      w.apiKey,
@@ -35,9 +43,6 @@ func (w *WebhookService) Set(webhookReq *WebhookUrl) error {
 	if err != nil {
 		return err
 	}
-	if statusCode != http.StatusNoContent {
-		return errors.New(string(resp))
-	}
 
 	return nil
 }
@@ -45,18 +50,22 @@ func (w *WebhookService) Set(webhookReq *WebhookUrl) error {
 // List:
 // doc: https://revolut-engineering.github.io/api-docs/merchant-api/#backend-api-backend-api-webhooks-retrieve-webhooks
 func (w *WebhookService) List() ([]*WebhookUrl, error) {
+	return w.ListWithContext(context.Background())
+}
+
+// ListWithContext: same as List, the request is bound to ctx for cancellation and deadline.
+func (w *WebhookService) ListWithContext(ctx context.Context) ([]*WebhookUrl, error) {
 
-	resp, statusCode, err := request.New(request.Config{
-		Method: http.MethodGet,
-		Url:    "https://merchant.revolut.com/api/1.0/webhooks",
-		ApiKey: w.apiKey,
+	resp, _, err := request.New(request.Config{
+		Context: ctx,
+		Options: w.options,
+		Method:  http.MethodGet,
+		Url:     "https://merchant.revolut.com/api/1.0/webhooks",
+		ApiKey:  w.apiKey,
 	})
 	if err != nil {
 		return nil, err
 	}
-	if statusCode != http.StatusOK {
-		return nil, errors.New(string(resp))
-	}
 
 	r := []*WebhookUrl{}
 	if err := json.Unmarshal(resp, &r); err != nil {
@@ -70,7 +79,7 @@ func (w *WebhookService) List() ([]*WebhookUrl, error) {
 //// doc: https://revolut-engineering.github.io/api-docs/business-api/#web-hooks-setting-up-a-web-hook
 //func (w *WebhookService) Delete() error {
 //
-//	resp, statusCode, err := request.New(request.Config{
+//	resp, _, err := request.New(request.Config{
 //		Method:      http.MethodDelete,
 //		Url:         "https://merchant.revolut.com/api/1.0/webhook",
 //		AccessToken: p.accessToken,
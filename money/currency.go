@@ -0,0 +1,152 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code, e.g. GBP.
+type Currency string
+
+// CurrencyInfo describes a currency of the ISO 4217 table.
+type CurrencyInfo struct {
+	// the alphabetic code, e.g. GBP
+	Code Currency
+	// the numeric code, e.g. 826
+	Numeric string
+	// the number of decimal places, e.g. 2 for GBP, 0 for JPY and 3 for BHD
+	MinorUnits int
+	// the English name, e.g. Pound Sterling
+	Name string
+}
+
+var (
+	byCode    = map[Currency]*CurrencyInfo{}
+	byNumeric = map[string]*CurrencyInfo{}
+)
+
+func init() {
+	for i := range currencies {
+		byCode[currencies[i].Code] = &currencies[i]
+		byNumeric[currencies[i].Numeric] = &currencies[i]
+	}
+}
+
+// Currencies returns the ISO 4217 table ordered by the alphabetic code.
+func Currencies() []CurrencyInfo {
+	return append([]CurrencyInfo(nil), currencies...)
+}
+
+// LookupNumeric returns the currency with the ISO 4217 numeric code, e.g. 826 for GBP.
+func LookupNumeric(numeric string) (CurrencyInfo, bool) {
+	if c, ok := byNumeric[numeric]; ok {
+		return *c, true
+	}
+
+	return CurrencyInfo{}, false
+}
+
+// ParseCurrency returns the currency with the alphabetic code, the case is ignored.
+func ParseCurrency(code string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+
+	return c, nil
+}
+
+// Info returns the ISO 4217 entry of c.
+func (c Currency) Info() (CurrencyInfo, bool) {
+	if i, ok := byCode[c]; ok {
+		return *i, true
+	}
+
+	return CurrencyInfo{}, false
+}
+
+// Validate fails when c is not an ISO 4217 currency code, e.g. a typo like GPB or a lowercase gbp.
+func (c Currency) Validate() error {
+	if _, ok := byCode[c]; !ok {
+		return fmt.Errorf("money: %q is not an ISO 4217 currency code", string(c))
+	}
+
+	return nil
+}
+
+// MinorUnits returns the number of decimal places of c, 2 for a currency missing in the table.
+func (c Currency) MinorUnits() int {
+	if i, ok := byCode[c]; ok {
+		return i.MinorUnits
+	}
+
+	return 2
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
+
+// FromMinor converts an amount in minor units of c to a Decimal, e.g. 1050 GBP pence to 10.50.
+func FromMinor(minor int64, c Currency) Decimal {
+	return New(minor, c.MinorUnits())
+}
+
+// ToMinor converts d to minor units of c, e.g. 10.5 GBP to 1050 pence.
+// It fails when d has more decimal places than the currency.
+func ToMinor(d Decimal, c Currency) (int64, error) {
+	r, err := d.Rescale(c.MinorUnits())
+	if err != nil {
+		return 0, fmt.Errorf("money: %s is not a valid amount in %s: %w", d, c, err)
+	}
+
+	return r.Units(), nil
+}
+
+// RoundTo rounds d half away from zero to the decimal places of c.
+func RoundTo(d Decimal, c Currency) Decimal {
+	return d.Round(c.MinorUnits())
+}
+
+// ValidateAmount fails when c is not a valid currency or d has more decimal places than c.
+func ValidateAmount(d Decimal, c Currency) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if _, err := ToMinor(d, c); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Format formats d for display in c, rounded to the decimal places of the currency with thousands separated,
+// e.g. "1,234.50 GBP" or "-1,000 JPY".
+func Format(d Decimal, c Currency) string {
+	r, err := RoundTo(d, c).Rescale(c.MinorUnits())
+	if err != nil {
+		r = d
+	}
+
+	s := r.Abs().String()
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i:]
+	}
+
+	var b strings.Builder
+	if r.Sign() < 0 {
+		b.WriteByte('-')
+	}
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	b.WriteString(fraction)
+	b.WriteString(" ")
+	b.WriteString(string(c))
+
+	return b.String()
+}
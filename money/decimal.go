@@ -0,0 +1,341 @@
+// Package money provides an exact decimal type for the amounts of the business and merchant APIs
+// and conversions between major and minor units of the ISO 4217 currencies.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MaxScale is the largest number of decimal places of a Decimal.
+const MaxScale = 18
+
+// ErrOverflow is returned when a value does not fit into a Decimal.
+var ErrOverflow = errors.New("money: decimal overflow")
+
+// Decimal is an exact decimal number, e.g. 10.25 is stored as 1025 units with the scale 2.
+// It holds up to 18 significant digits, the zero value is 0. Arithmetic panics with ErrOverflow
+// when the result does not fit, far beyond any amount handled by the API.
+type Decimal struct {
+	units int64
+	scale uint8
+}
+
+var pow10 = func() [MaxScale + 1]int64 {
+	var p [MaxScale + 1]int64
+	p[0] = 1
+	for i := 1; i <= MaxScale; i++ {
+		p[i] = p[i-1] * 10
+	}
+	return p
+}()
+
+// New returns units * 10^-scale, e.g. New(1025, 2) is 10.25.
+func New(units int64, scale int) Decimal {
+	if scale < 0 || scale > MaxScale {
+		panic(fmt.Sprintf("money: scale %d out of range", scale))
+	}
+
+	return Decimal{units: units, scale: uint8(scale)}
+}
+
+// NewFromInt returns the integer i.
+func NewFromInt(i int64) Decimal {
+	return Decimal{units: i}
+}
+
+// NewFromFloat returns the shortest decimal representation of f, e.g. 0.1 is exactly 0.1.
+func NewFromFloat(f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("money: %v is not a number", f)
+	}
+
+	return Parse(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// Parse parses a decimal number such as "10.25", "-3", "1e-2" or "1.5E3".
+func Parse(s string) (Decimal, error) {
+	orig := s
+	if s == "" {
+		return Decimal{}, fmt.Errorf("money: invalid decimal %q", orig)
+	}
+
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf("money: invalid decimal %q", orig)
+		}
+		exp, s = e, s[:i]
+	}
+	if s == "" {
+		return Decimal{}, fmt.Errorf("money: invalid decimal %q", orig)
+	}
+
+	neg := false
+	switch s[0] {
+	case '-':
+		neg, s = true, s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	digits, scale := s, 0
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits, scale = s[:i]+s[i+1:], len(s)-i-1
+	}
+	if digits == "" {
+		return Decimal{}, fmt.Errorf("money: invalid decimal %q", orig)
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return Decimal{}, fmt.Errorf("money: invalid decimal %q", orig)
+		}
+	}
+
+	scale -= exp
+	if scale < -MaxScale-1 {
+		return Decimal{}, fmt.Errorf("money: %q: %w", orig, ErrOverflow)
+	}
+	for scale < 0 {
+		digits += "0"
+		scale++
+	}
+	// trailing zeros beyond the supported scale carry no value
+	for scale > MaxScale && strings.HasSuffix(digits, "0") {
+		digits = digits[:len(digits)-1]
+		scale--
+	}
+	if scale > MaxScale {
+		return Decimal{}, fmt.Errorf("money: %q has more than %d decimal places", orig, MaxScale)
+	}
+
+	var units int64
+	if digits = strings.TrimLeft(digits, "0"); digits != "" {
+		var err error
+		if units, err = strconv.ParseInt(digits, 10, 64); err != nil {
+			return Decimal{}, fmt.Errorf("money: %q: %w", orig, ErrOverflow)
+		}
+	}
+	if neg {
+		units = -units
+	}
+
+	return Decimal{units: units, scale: uint8(scale)}, nil
+}
+
+// MustParse is like Parse but panics when s is invalid, e.g. for constants.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// Units returns the unscaled value of d, e.g. 1025 for 10.25.
+func (d Decimal) Units() int64 {
+	return d.units
+}
+
+// Scale returns the number of decimal places of d, e.g. 2 for 10.25.
+func (d Decimal) Scale() int {
+	return int(d.scale)
+}
+
+// IsZero reports whether d is 0.
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+// Sign returns -1, 0 or 1 when d is negative, zero or positive.
+func (d Decimal) Sign() int {
+	switch {
+	case d.units < 0:
+		return -1
+	case d.units > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{units: -d.units, scale: d.scale}
+}
+
+// Abs returns |d|.
+func (d Decimal) Abs() Decimal {
+	if d.units < 0 {
+		return d.Neg()
+	}
+
+	return d
+}
+
+// Add returns d + o with the larger scale of the two.
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b := align(d, o)
+
+	return fromBig(new(big.Int).Add(big.NewInt(a.units), big.NewInt(b.units)), int(a.scale))
+}
+
+// Sub returns d - o with the larger scale of the two.
+func (d Decimal) Sub(o Decimal) Decimal {
+	return d.Add(o.Neg())
+}
+
+// Mul returns d * o, the result is rounded to MaxScale decimal places when needed.
+func (d Decimal) Mul(o Decimal) Decimal {
+	p := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(o.units))
+	scale := int(d.scale) + int(o.scale)
+	if scale > MaxScale {
+		p = roundBig(p, scale-MaxScale)
+		scale = MaxScale
+	}
+
+	return fromBig(p, scale)
+}
+
+// Cmp returns -1, 0 or 1 when d is less than, equal to or greater than o.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b := align(d, o)
+	switch {
+	case a.units < b.units:
+		return -1
+	case a.units > b.units:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Equal reports whether d and o are the same number, e.g. 1.5 and 1.50.
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+// Round rounds d half away from zero to scale decimal places, e.g. 2.345 to 2.35.
+// A d with less decimal places is returned with the scale unchanged.
+func (d Decimal) Round(scale int) Decimal {
+	if scale < 0 || int(d.scale) <= scale {
+		return d
+	}
+
+	return fromBig(roundBig(big.NewInt(d.units), int(d.scale)-scale), scale)
+}
+
+// Rescale returns d with exactly scale decimal places, it fails when d has non-zero digits beyond scale.
+func (d Decimal) Rescale(scale int) (Decimal, error) {
+	if scale < 0 || scale > MaxScale {
+		return Decimal{}, fmt.Errorf("money: scale %d out of range", scale)
+	}
+	if int(d.scale) > scale {
+		r := d.Round(scale)
+		if !r.Equal(d) {
+			return Decimal{}, fmt.Errorf("money: %s has more than %d decimal places", d, scale)
+		}
+		return r, nil
+	}
+
+	u := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(pow10[scale-int(d.scale)]))
+	if !u.IsInt64() {
+		return Decimal{}, ErrOverflow
+	}
+
+	return Decimal{units: u.Int64(), scale: uint8(scale)}, nil
+}
+
+// Float64 returns the nearest float64 to d, for display or statistics only.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+
+	return f
+}
+
+// String formats d with all its decimal places, e.g. "10.50".
+func (d Decimal) String() string {
+	s := strconv.FormatInt(d.units, 10)
+	if d.scale == 0 {
+		return s
+	}
+
+	neg := d.units < 0
+	if neg {
+		s = s[1:]
+	}
+	if len(s) <= int(d.scale) {
+		s = strings.Repeat("0", int(d.scale)-len(s)+1) + s
+	}
+	s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	if neg {
+		s = "-" + s
+	}
+
+	return s
+}
+
+// MarshalJSON encodes d as a JSON number with all its decimal places.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or a string holding a number without loss of precision.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+
+	return nil
+}
+
+// align returns d and o with the same scale.
+func align(d, o Decimal) (Decimal, Decimal) {
+	var err error
+	switch {
+	case d.scale < o.scale:
+		d, err = d.Rescale(int(o.scale))
+	case d.scale > o.scale:
+		o, err = o.Rescale(int(d.scale))
+	}
+	if err != nil {
+		panic(ErrOverflow)
+	}
+
+	return d, o
+}
+
+// roundBig divides u by 10^digits rounding half away from zero.
+func roundBig(u *big.Int, digits int) *big.Int {
+	p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	q, r := new(big.Int).QuoRem(u, p, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(p) >= 0 {
+		q.Add(q, big.NewInt(int64(u.Sign())))
+	}
+
+	return q
+}
+
+func fromBig(u *big.Int, scale int) Decimal {
+	if !u.IsInt64() {
+		panic(ErrOverflow)
+	}
+
+	return Decimal{units: u.Int64(), scale: uint8(scale)}
+}
@@ -0,0 +1,145 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in    string
+		units int64
+		scale int
+	}{
+		{"0", 0, 0},
+		{"10.25", 1025, 2},
+		{"-3", -3, 0},
+		{"+3.50", 350, 2},
+		{".5", 5, 1},
+		{"5.", 5, 0},
+		{"1e-2", 1, 2},
+		{"1.5E3", 1500, 0},
+		{"-0.001", -1, 3},
+		{"0.100000000000000000000", 100000000000000000, 18},
+		{"9223372036854775807", 9223372036854775807, 0},
+	}
+	for _, tt := range tests {
+		d, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if d.Units() != tt.units || d.Scale() != tt.scale {
+			t.Errorf("Parse(%q) = %d scale %d, want %d scale %d", tt.in, d.Units(), d.Scale(), tt.units, tt.scale)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{"", "e5", "E1", "-", "+", "-e5", ".", "-.", "1e", "1e+", "abc", "1.2.3", "1,5", " 1", "0x10", "1.0000000000000000001"} {
+		if d, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", in, d)
+		}
+	}
+}
+
+func TestParseOverflow(t *testing.T) {
+	for _, in := range []string{"9223372036854775808", "-9223372036854775809", "1e19", "1e100"} {
+		if _, err := Parse(in); !errors.Is(err, ErrOverflow) {
+			t.Errorf("Parse(%q) = %v, want ErrOverflow", in, err)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		d    Decimal
+		want string
+	}{
+		{Decimal{}, "0"},
+		{New(1025, 2), "10.25"},
+		{New(1050, 2), "10.50"},
+		{New(-5, 3), "-0.005"},
+		{New(5, 1), "0.5"},
+		{New(-1200, 0), "-1200"},
+		{New(0, 2), "0.00"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("New(%d, %d).String() = %q, want %q", tt.d.Units(), tt.d.Scale(), got, tt.want)
+		}
+	}
+}
+
+func TestRescale(t *testing.T) {
+	tests := []struct {
+		in    string
+		scale int
+		want  string
+		err   bool
+	}{
+		{"10.5", 2, "10.50", false},
+		{"10", 3, "10.000", false},
+		{"10.500", 1, "10.5", false},
+		{"10.25", 1, "", true},
+		{"-0.010", 2, "-0.01", false},
+		{"1", -1, "", true},
+		{"1", MaxScale + 1, "", true},
+		{"9223372036854775807", 1, "", true},
+	}
+	for _, tt := range tests {
+		got, err := MustParse(tt.in).Rescale(tt.scale)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Rescale(%s, %d) = %s, want an error", tt.in, tt.scale, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Rescale(%s, %d): %v", tt.in, tt.scale, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("Rescale(%s, %d) = %s, want %s", tt.in, tt.scale, got, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type amount struct {
+		Amount Decimal `json:"amount"`
+	}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`{"amount":10.50}`, `{"amount":10.50}`},
+		{`{"amount":"0.1"}`, `{"amount":0.1}`},
+		{`{"amount":-1e-2}`, `{"amount":-0.01}`},
+		{`{"amount":123456789.123456789}`, `{"amount":123456789.123456789}`},
+		{`{"amount":null}`, `{"amount":0}`},
+	}
+	for _, tt := range tests {
+		var a amount
+		if err := json.Unmarshal([]byte(tt.in), &a); err != nil {
+			t.Errorf("Unmarshal(%s): %v", tt.in, err)
+			continue
+		}
+		b, err := json.Marshal(a)
+		if err != nil {
+			t.Errorf("Marshal(%s): %v", tt.in, err)
+			continue
+		}
+		if string(b) != tt.want {
+			t.Errorf("round trip of %s = %s, want %s", tt.in, b, tt.want)
+		}
+	}
+
+	for _, in := range []string{`{"amount":"e1"}`, `{"amount":""}`, `{"amount":"-"}`, `{"amount":true}`} {
+		var a amount
+		if err := json.Unmarshal([]byte(in), &a); err == nil {
+			t.Errorf("Unmarshal(%s) = %s, want an error", in, a.Amount)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package money
+
+// currencies is the ISO 4217 table of the active currencies, the funds and precious metals without minor units are omitted.
+var currencies = []CurrencyInfo{
+	{Code: "AED", Numeric: "784", MinorUnits: 2, Name: "UAE Dirham"},
+	{Code: "AFN", Numeric: "971", MinorUnits: 2, Name: "Afghani"},
+	{Code: "ALL", Numeric: "008", MinorUnits: 2, Name: "Lek"},
+	{Code: "AMD", Numeric: "051", MinorUnits: 2, Name: "Armenian Dram"},
+	{Code: "ANG", Numeric: "532", MinorUnits: 2, Name: "Netherlands Antillean Guilder"},
+	{Code: "AOA", Numeric: "973", MinorUnits: 2, Name: "Kwanza"},
+	{Code: "ARS", Numeric: "032", MinorUnits: 2, Name: "Argentine Peso"},
+	{Code: "AUD", Numeric: "036", MinorUnits: 2, Name: "Australian Dollar"},
+	{Code: "AWG", Numeric: "533", MinorUnits: 2, Name: "Aruban Florin"},
+	{Code: "AZN", Numeric: "944", MinorUnits: 2, Name: "Azerbaijan Manat"},
+	{Code: "BAM", Numeric: "977", MinorUnits: 2, Name: "Convertible Mark"},
+	{Code: "BBD", Numeric: "052", MinorUnits: 2, Name: "Barbados Dollar"},
+	{Code: "BDT", Numeric: "050", MinorUnits: 2, Name: "Taka"},
+	{Code: "BGN", Numeric: "975", MinorUnits: 2, Name: "Bulgarian Lev"},
+	{Code: "BHD", Numeric: "048", MinorUnits: 3, Name: "Bahraini Dinar"},
+	{Code: "BIF", Numeric: "108", MinorUnits: 0, Name: "Burundi Franc"},
+	{Code: "BMD", Numeric: "060", MinorUnits: 2, Name: "Bermudian Dollar"},
+	{Code: "BND", Numeric: "096", MinorUnits: 2, Name: "Brunei Dollar"},
+	{Code: "BOB", Numeric: "068", MinorUnits: 2, Name: "Boliviano"},
+	{Code: "BOV", Numeric: "984", MinorUnits: 2, Name: "Mvdol"},
+	{Code: "BRL", Numeric: "986", MinorUnits: 2, Name: "Brazilian Real"},
+	{Code: "BSD", Numeric: "044", MinorUnits: 2, Name: "Bahamian Dollar"},
+	{Code: "BTN", Numeric: "064", MinorUnits: 2, Name: "Ngultrum"},
+	{Code: "BWP", Numeric: "072", MinorUnits: 2, Name: "Pula"},
+	{Code: "BYN", Numeric: "933", MinorUnits: 2, Name: "Belarusian Ruble"},
+	{Code: "BZD", Numeric: "084", MinorUnits: 2, Name: "Belize Dollar"},
+	{Code: "CAD", Numeric: "124", MinorUnits: 2, Name: "Canadian Dollar"},
+	{Code: "CDF", Numeric: "976", MinorUnits: 2, Name: "Congolese Franc"},
+	{Code: "CHE", Numeric: "947", MinorUnits: 2, Name: "WIR Euro"},
+	{Code: "CHF", Numeric: "756", MinorUnits: 2, Name: "Swiss Franc"},
+	{Code: "CHW", Numeric: "948", MinorUnits: 2, Name: "WIR Franc"},
+	{Code: "CLF", Numeric: "990", MinorUnits: 4, Name: "Unidad de Fomento"},
+	{Code: "CLP", Numeric: "152", MinorUnits: 0, Name: "Chilean Peso"},
+	{Code: "CNY", Numeric: "156", MinorUnits: 2, Name: "Yuan Renminbi"},
+	{Code: "COP", Numeric: "170", MinorUnits: 2, Name: "Colombian Peso"},
+	{Code: "COU", Numeric: "970", MinorUnits: 2, Name: "Unidad de Valor Real"},
+	{Code: "CRC", Numeric: "188", MinorUnits: 2, Name: "Costa Rican Colon"},
+	{Code: "CUP", Numeric: "192", MinorUnits: 2, Name: "Cuban Peso"},
+	{Code: "CVE", Numeric: "132", MinorUnits: 2, Name: "Cabo Verde Escudo"},
+	{Code: "CZK", Numeric: "203", MinorUnits: 2, Name: "Czech Koruna"},
+	{Code: "DJF", Numeric: "262", MinorUnits: 0, Name: "Djibouti Franc"},
+	{Code: "DKK", Numeric: "208", MinorUnits: 2, Name: "Danish Krone"},
+	{Code: "DOP", Numeric: "214", MinorUnits: 2, Name: "Dominican Peso"},
+	{Code: "DZD", Numeric: "012", MinorUnits: 2, Name: "Algerian Dinar"},
+	{Code: "EGP", Numeric: "818", MinorUnits: 2, Name: "Egyptian Pound"},
+	{Code: "ERN", Numeric: "232", MinorUnits: 2, Name: "Nakfa"},
+	{Code: "ETB", Numeric: "230", MinorUnits: 2, Name: "Ethiopian Birr"},
+	{Code: "EUR", Numeric: "978", MinorUnits: 2, Name: "Euro"},
+	{Code: "FJD", Numeric: "242", MinorUnits: 2, Name: "Fiji Dollar"},
+	{Code: "FKP", Numeric: "238", MinorUnits: 2, Name: "Falkland Islands Pound"},
+	{Code: "GBP", Numeric: "826", MinorUnits: 2, Name: "Pound Sterling"},
+	{Code: "GEL", Numeric: "981", MinorUnits: 2, Name: "Lari"},
+	{Code: "GHS", Numeric: "936", MinorUnits: 2, Name: "Ghana Cedi"},
+	{Code: "GIP", Numeric: "292", MinorUnits: 2, Name: "Gibraltar Pound"},
+	{Code: "GMD", Numeric: "270", MinorUnits: 2, Name: "Dalasi"},
+	{Code: "GNF", Numeric: "324", MinorUnits: 0, Name: "Guinean Franc"},
+	{Code: "GTQ", Numeric: "320", MinorUnits: 2, Name: "Quetzal"},
+	{Code: "GYD", Numeric: "328", MinorUnits: 2, Name: "Guyana Dollar"},
+	{Code: "HKD", Numeric: "344", MinorUnits: 2, Name: "Hong Kong Dollar"},
+	{Code: "HNL", Numeric: "340", MinorUnits: 2, Name: "Lempira"},
+	{Code: "HTG", Numeric: "332", MinorUnits: 2, Name: "Gourde"},
+	{Code: "HUF", Numeric: "348", MinorUnits: 2, Name: "Forint"},
+	{Code: "IDR", Numeric: "360", MinorUnits: 2, Name: "Rupiah"},
+	{Code: "ILS", Numeric: "376", MinorUnits: 2, Name: "New Israeli Sheqel"},
+	{Code: "INR", Numeric: "356", MinorUnits: 2, Name: "Indian Rupee"},
+	{Code: "IQD", Numeric: "368", MinorUnits: 3, Name: "Iraqi Dinar"},
+	{Code: "IRR", Numeric: "364", MinorUnits: 2, Name: "Iranian Rial"},
+	{Code: "ISK", Numeric: "352", MinorUnits: 0, Name: "Iceland Krona"},
+	{Code: "JMD", Numeric: "388", MinorUnits: 2, Name: "Jamaican Dollar"},
+	{Code: "JOD", Numeric: "400", MinorUnits: 3, Name: "Jordanian Dinar"},
+	{Code: "JPY", Numeric: "392", MinorUnits: 0, Name: "Yen"},
+	{Code: "KES", Numeric: "404", MinorUnits: 2, Name: "Kenyan Shilling"},
+	{Code: "KGS", Numeric: "417", MinorUnits: 2, Name: "Som"},
+	{Code: "KHR", Numeric: "116", MinorUnits: 2, Name: "Riel"},
+	{Code: "KMF", Numeric: "174", MinorUnits: 0, Name: "Comorian Franc"},
+	{Code: "KPW", Numeric: "408", MinorUnits: 2, Name: "North Korean Won"},
+	{Code: "KRW", Numeric: "410", MinorUnits: 0, Name: "Won"},
+	{Code: "KWD", Numeric: "414", MinorUnits: 3, Name: "Kuwaiti Dinar"},
+	{Code: "KYD", Numeric: "136", MinorUnits: 2, Name: "Cayman Islands Dollar"},
+	{Code: "KZT", Numeric: "398", MinorUnits: 2, Name: "Tenge"},
+	{Code: "LAK", Numeric: "418", MinorUnits: 2, Name: "Lao Kip"},
+	{Code: "LBP", Numeric: "422", MinorUnits: 2, Name: "Lebanese Pound"},
+	{Code: "LKR", Numeric: "144", MinorUnits: 2, Name: "Sri Lanka Rupee"},
+	{Code: "LRD", Numeric: "430", MinorUnits: 2, Name: "Liberian Dollar"},
+	{Code: "LSL", Numeric: "426", MinorUnits: 2, Name: "Loti"},
+	{Code: "LYD", Numeric: "434", MinorUnits: 3, Name: "Libyan Dinar"},
+	{Code: "MAD", Numeric: "504", MinorUnits: 2, Name: "Moroccan Dirham"},
+	{Code: "MDL", Numeric: "498", MinorUnits: 2, Name: "Moldovan Leu"},
+	{Code: "MGA", Numeric: "969", MinorUnits: 2, Name: "Malagasy Ariary"},
+	{Code: "MKD", Numeric: "807", MinorUnits: 2, Name: "Denar"},
+	{Code: "MMK", Numeric: "104", MinorUnits: 2, Name: "Kyat"},
+	{Code: "MNT", Numeric: "496", MinorUnits: 2, Name: "Tugrik"},
+	{Code: "MOP", Numeric: "446", MinorUnits: 2, Name: "Pataca"},
+	{Code: "MRU", Numeric: "929", MinorUnits: 2, Name: "Ouguiya"},
+	{Code: "MUR", Numeric: "480", MinorUnits: 2, Name: "Mauritius Rupee"},
+	{Code: "MVR", Numeric: "462", MinorUnits: 2, Name: "Rufiyaa"},
+	{Code: "MWK", Numeric: "454", MinorUnits: 2, Name: "Malawi Kwacha"},
+	{Code: "MXN", Numeric: "484", MinorUnits: 2, Name: "Mexican Peso"},
+	{Code: "MXV", Numeric: "979", MinorUnits: 2, Name: "Mexican Unidad de Inversion (UDI)"},
+	{Code: "MYR", Numeric: "458", MinorUnits: 2, Name: "Malaysian Ringgit"},
+	{Code: "MZN", Numeric: "943", MinorUnits: 2, Name: "Mozambique Metical"},
+	{Code: "NAD", Numeric: "516", MinorUnits: 2, Name: "Namibia Dollar"},
+	{Code: "NGN", Numeric: "566", MinorUnits: 2, Name: "Naira"},
+	{Code: "NIO", Numeric: "558", MinorUnits: 2, Name: "Cordoba Oro"},
+	{Code: "NOK", Numeric: "578", MinorUnits: 2, Name: "Norwegian Krone"},
+	{Code: "NPR", Numeric: "524", MinorUnits: 2, Name: "Nepalese Rupee"},
+	{Code: "NZD", Numeric: "554", MinorUnits: 2, Name: "New Zealand Dollar"},
+	{Code: "OMR", Numeric: "512", MinorUnits: 3, Name: "Rial Omani"},
+	{Code: "PAB", Numeric: "590", MinorUnits: 2, Name: "Balboa"},
+	{Code: "PEN", Numeric: "604", MinorUnits: 2, Name: "Sol"},
+	{Code: "PGK", Numeric: "598", MinorUnits: 2, Name: "Kina"},
+	{Code: "PHP", Numeric: "608", MinorUnits: 2, Name: "Philippine Peso"},
+	{Code: "PKR", Numeric: "586", MinorUnits: 2, Name: "Pakistan Rupee"},
+	{Code: "PLN", Numeric: "985", MinorUnits: 2, Name: "Zloty"},
+	{Code: "PYG", Numeric: "600", MinorUnits: 0, Name: "Guarani"},
+	{Code: "QAR", Numeric: "634", MinorUnits: 2, Name: "Qatari Rial"},
+	{Code: "RON", Numeric: "946", MinorUnits: 2, Name: "Romanian Leu"},
+	{Code: "RSD", Numeric: "941", MinorUnits: 2, Name: "Serbian Dinar"},
+	{Code: "RUB", Numeric: "643", MinorUnits: 2, Name: "Russian Ruble"},
+	{Code: "RWF", Numeric: "646", MinorUnits: 0, Name: "Rwanda Franc"},
+	{Code: "SAR", Numeric: "682", MinorUnits: 2, Name: "Saudi Riyal"},
+	{Code: "SBD", Numeric: "090", MinorUnits: 2, Name: "Solomon Islands Dollar"},
+	{Code: "SCR", Numeric: "690", MinorUnits: 2, Name: "Seychelles Rupee"},
+	{Code: "SDG", Numeric: "938", MinorUnits: 2, Name: "Sudanese Pound"},
+	{Code: "SEK", Numeric: "752", MinorUnits: 2, Name: "Swedish Krona"},
+	{Code: "SGD", Numeric: "702", MinorUnits: 2, Name: "Singapore Dollar"},
+	{Code: "SHP", Numeric: "654", MinorUnits: 2, Name: "Saint Helena Pound"},
+	{Code: "SLE", Numeric: "925", MinorUnits: 2, Name: "Leone"},
+	{Code: "SOS", Numeric: "706", MinorUnits: 2, Name: "Somali Shilling"},
+	{Code: "SRD", Numeric: "968", MinorUnits: 2, Name: "Surinam Dollar"},
+	{Code: "SSP", Numeric: "728", MinorUnits: 2, Name: "South Sudanese Pound"},
+	{Code: "STN", Numeric: "930", MinorUnits: 2, Name: "Dobra"},
+	{Code: "SVC", Numeric: "222", MinorUnits: 2, Name: "El Salvador Colon"},
+	{Code: "SYP", Numeric: "760", MinorUnits: 2, Name: "Syrian Pound"},
+	{Code: "SZL", Numeric: "748", MinorUnits: 2, Name: "Lilangeni"},
+	{Code: "THB", Numeric: "764", MinorUnits: 2, Name: "Baht"},
+	{Code: "TJS", Numeric: "972", MinorUnits: 2, Name: "Somoni"},
+	{Code: "TMT", Numeric: "934", MinorUnits: 2, Name: "Turkmenistan New Manat"},
+	{Code: "TND", Numeric: "788", MinorUnits: 3, Name: "Tunisian Dinar"},
+	{Code: "TOP", Numeric: "776", MinorUnits: 2, Name: "Pa'anga"},
+	{Code: "TRY", Numeric: "949", MinorUnits: 2, Name: "Turkish Lira"},
+	{Code: "TTD", Numeric: "780", MinorUnits: 2, Name: "Trinidad and Tobago Dollar"},
+	{Code: "TWD", Numeric: "901", MinorUnits: 2, Name: "New Taiwan Dollar"},
+	{Code: "TZS", Numeric: "834", MinorUnits: 2, Name: "Tanzanian Shilling"},
+	{Code: "UAH", Numeric: "980", MinorUnits: 2, Name: "Hryvnia"},
+	{Code: "UGX", Numeric: "800", MinorUnits: 0, Name: "Uganda Shilling"},
+	{Code: "USD", Numeric: "840", MinorUnits: 2, Name: "US Dollar"},
+	{Code: "USN", Numeric: "997", MinorUnits: 2, Name: "US Dollar (Next day)"},
+	{Code: "UYI", Numeric: "940", MinorUnits: 0, Name: "Uruguay Peso en Unidades Indexadas (UI)"},
+	{Code: "UYU", Numeric: "858", MinorUnits: 2, Name: "Peso Uruguayo"},
+	{Code: "UYW", Numeric: "927", MinorUnits: 4, Name: "Unidad Previsional"},
+	{Code: "UZS", Numeric: "860", MinorUnits: 2, Name: "Uzbekistan Sum"},
+	{Code: "VED", Numeric: "926", MinorUnits: 2, Name: "Bolivar Soberano"},
+	{Code: "VES", Numeric: "928", MinorUnits: 2, Name: "Bolivar Soberano"},
+	{Code: "VND", Numeric: "704", MinorUnits: 0, Name: "Dong"},
+	{Code: "VUV", Numeric: "548", MinorUnits: 0, Name: "Vatu"},
+	{Code: "WST", Numeric: "882", MinorUnits: 2, Name: "Tala"},
+	{Code: "XAF", Numeric: "950", MinorUnits: 0, Name: "CFA Franc BEAC"},
+	{Code: "XCD", Numeric: "951", MinorUnits: 2, Name: "East Caribbean Dollar"},
+	{Code: "XOF", Numeric: "952", MinorUnits: 0, Name: "CFA Franc BCEAO"},
+	{Code: "XPF", Numeric: "953", MinorUnits: 0, Name: "CFP Franc"},
+	{Code: "YER", Numeric: "886", MinorUnits: 2, Name: "Yemeni Rial"},
+	{Code: "ZAR", Numeric: "710", MinorUnits: 2, Name: "Rand"},
+	{Code: "ZMW", Numeric: "967", MinorUnits: 2, Name: "Zambian Kwacha"},
+	{Code: "ZWG", Numeric: "924", MinorUnits: 2, Name: "Zimbabwe Gold"},
+}
@@ -0,0 +1,114 @@
+package revoluttest
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	business "github.com/rysavyvladan/go-revolut/business/1.0"
+	"github.com/rysavyvladan/go-revolut/money"
+)
+
+func batchReqs(accountId string, cp *business.CounterpartyResp, n int) []*business.PaymentReq {
+	reqs := make([]*business.PaymentReq, n)
+	for i := range reqs {
+		reqs[i] = paymentReq(accountId, cp, fmt.Sprintf("invoice-%d", i+1))
+	}
+
+	return reqs
+}
+
+func TestBatchResume(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		journal func(t *testing.T) business.PaymentJournal
+	}{
+		{"memory", func(t *testing.T) business.PaymentJournal { return business.NewMemoryPaymentJournal() }},
+		{"file", func(t *testing.T) business.PaymentJournal {
+			return business.NewFilePaymentJournal(filepath.Join(tempDir(t), "journal.jsonl"))
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewServer()
+			defer srv.Close()
+			bC := newBusinessClient(t, srv)
+			gbp, cp := payee(t, bC)
+			reqs := batchReqs(gbp, cp, 5)
+			journal := tt.journal(t)
+
+			// the payments are made but the responses of the first two are lost, and so are their lookups
+			srv.InjectFault(Fault{Method: http.MethodPost, Path: "/api/1.0/pay", Times: 2, Drop: true, AfterProcessing: true})
+			srv.InjectFault(Fault{Method: http.MethodGet, Path: "/api/1.0/transaction/*", Times: 2})
+			report, err := bC.Payment().CreateBatch(reqs, &business.BatchOptions{Concurrency: 1, Journal: journal})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := len(report.NotCreated()); n != 2 {
+				t.Fatalf("got %d payments not created, want 2: %s", n, report)
+			}
+
+			// the payments recorded as attempted are looked up by their request ID instead of being made again
+			report, err = bC.Payment().CreateBatch(reqs, &business.BatchOptions{Concurrency: 1, Journal: journal})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := len(report.NotCreated()); n != 0 {
+				t.Errorf("got %d payments not created after the resume, want 0: %s", n, report)
+			}
+			resumed := 0
+			for _, res := range report.Results {
+				if res.Resumed {
+					resumed++
+				}
+			}
+			if resumed != 3 {
+				t.Errorf("got %d resumed payments, want 3", resumed)
+			}
+			if n := count(srv, http.MethodGet, "/api/1.0/transaction/"); n != 4 {
+				t.Errorf("got %d lookups, want 2 failed and 2 on the resume", n)
+			}
+
+			// each payment of 25 GBP was made once
+			if b := srv.Balance(gbp); !b.Equal(money.MustParse("9875")) {
+				t.Errorf("got the balance %v, want 9875", b)
+			}
+			if n := count(srv, http.MethodPost, "/api/1.0/pay"); n != 5 {
+				t.Errorf("got %d payment requests, want 5", n)
+			}
+		})
+	}
+}
+
+func TestBatchRecordedAttemptNotMade(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv)
+	gbp, cp := payee(t, bC)
+	reqs := batchReqs(gbp, cp, 2)
+
+	// an earlier run recorded the attempt of invoice-1 and was interrupted before making it
+	journal := business.NewMemoryPaymentJournal()
+	if err := journal.Save(&business.JournalEntry{RequestId: "invoice-1", UpdatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	report, err := bC.Payment().CreateBatch(reqs, &business.BatchOptions{Journal: journal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(report.NotCreated()); n != 0 {
+		t.Fatalf("got %d payments not created, want 0: %s", n, report)
+	}
+	if n := count(srv, http.MethodGet, "/api/1.0/transaction/"); n != 1 {
+		t.Errorf("got %d lookups, want 1", n)
+	}
+	if b := srv.Balance(gbp); !b.Equal(money.MustParse("9950")) {
+		t.Errorf("got the balance %v, want 9950", b)
+	}
+	for _, req := range reqs {
+		if e, _ := journal.Load(req.RequestId); e == nil || e.TransactionId == "" {
+			t.Errorf("got the entry %v of %s, want its transaction", e, req.RequestId)
+		}
+	}
+}
@@ -0,0 +1,968 @@
+package revoluttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rysavyvladan/go-revolut/money"
+)
+
+// the states of transactions, see business.PaymentState
+const (
+	statePending   = "pending"
+	stateCompleted = "completed"
+	stateDeclined  = "declined"
+	stateFailed    = "failed"
+)
+
+type account struct {
+	Id        string        `json:"id"`
+	Name      string        `json:"name"`
+	Balance   money.Decimal `json:"balance"`
+	Currency  string        `json:"currency"`
+	State     string        `json:"state"`
+	Public    bool          `json:"public"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+type name struct {
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+}
+
+type counterpartyAccount struct {
+	Id               string `json:"id"`
+	Currency         string `json:"currency"`
+	Type             string `json:"type"`
+	AccountNo        string `json:"account_no,omitempty"`
+	Iban             string `json:"iban,omitempty"`
+	SortCode         string `json:"sort_code,omitempty"`
+	RoutingNumber    string `json:"routing_number,omitempty"`
+	Bic              string `json:"bic,omitempty"`
+	Clabe            string `json:"clabe,omitempty"`
+	Ifsc             string `json:"ifsc,omitempty"`
+	BsbCode          string `json:"bsb_code,omitempty"`
+	Email            string `json:"email,omitempty"`
+	Name             string `json:"name,omitempty"`
+	BankCountry      string `json:"bank_country,omitempty"`
+	RecipientCharges string `json:"recipient_charges,omitempty"`
+}
+
+type counterparty struct {
+	Id          string                `json:"id"`
+	Name        string                `json:"name"`
+	Phone       string                `json:"phone,omitempty"`
+	ProfileType string                `json:"profile_type,omitempty"`
+	Country     string                `json:"country,omitempty"`
+	State       string                `json:"state"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+	Accounts    []counterpartyAccount `json:"accounts"`
+}
+
+type legCounterparty struct {
+	Id        string `json:"id,omitempty"`
+	Type      string `json:"type"`
+	AccountId string `json:"account_id,omitempty"`
+}
+
+type leg struct {
+	LegId        string           `json:"leg_id"`
+	AccountId    string           `json:"account_id"`
+	Counterparty *legCounterparty `json:"counterparty,omitempty"`
+	Amount       money.Decimal    `json:"amount"`
+	Currency     string           `json:"currency"`
+	Description  string           `json:"description,omitempty"`
+	Balance      money.Decimal    `json:"balance"`
+}
+
+type transaction struct {
+	Id           string     `json:"id"`
+	Type         string     `json:"type"`
+	RequestId    string     `json:"request_id,omitempty"`
+	State        string     `json:"state"`
+	ReasonCode   string     `json:"reason_code,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	ScheduledFor string     `json:"scheduled_for,omitempty"`
+	Reference    string     `json:"reference,omitempty"`
+	Legs         []leg      `json:"legs"`
+}
+
+type paymentDraftPayment struct {
+	Id       string `json:"id"`
+	Amount   amount `json:"amount"`
+	Currency string `json:"currency,omitempty"`
+	Account  string `json:"account_id"`
+	Receiver struct {
+		CounterpartyId string `json:"counterparty_id"`
+		AccountId      string `json:"account_id,omitempty"`
+	} `json:"receiver"`
+	State     string `json:"state"`
+	Reference string `json:"reference,omitempty"`
+}
+
+type paymentDraft struct {
+	Id           string                `json:"-"`
+	ScheduledFor string                `json:"scheduled_for,omitempty"`
+	Title        string                `json:"title,omitempty"`
+	Payments     []paymentDraftPayment `json:"payments"`
+}
+
+type amount struct {
+	Amount   money.Decimal `json:"amount"`
+	Currency string        `json:"currency"`
+}
+
+// AddAccount adds an active account and returns its ID.
+func (s *Server) AddAccount(name, currency string, balance money.Decimal) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	a := &account{
+		Id:        newId(),
+		Name:      name,
+		Balance:   balance,
+		Currency:  currency,
+		State:     "active",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.accounts = append(s.accounts, a)
+
+	return a.Id
+}
+
+// Balance returns the balance of an account, 0 when the account does not exist.
+func (s *Server) Balance(accountId string) money.Decimal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if a := s.account(accountId); a != nil {
+		return a.Balance
+	}
+
+	return money.Decimal{}
+}
+
+// SetRate sets the exchange rate between two currencies, e.g. SetRate("GBP", "EUR", 1.15).
+func (s *Server) SetRate(from, to string, rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rates[from+"/"+to] = rate
+}
+
+// SetWebhook sets the URL the Business API events are posted to, the same as WebhookService.Set.
+func (s *Server) SetWebhook(url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.webhookUrl = url
+}
+
+// CompleteTransaction moves a pending transaction to the completed state.
+func (s *Server) CompleteTransaction(id string) error {
+	return s.finish(id, stateCompleted, "")
+}
+
+// DeclineTransaction moves a pending transaction to the declined state and returns the money to the account.
+func (s *Server) DeclineTransaction(id, reasonCode string) error {
+	return s.finish(id, stateDeclined, reasonCode)
+}
+
+// FailTransaction moves a pending transaction to the failed state and returns the money to the account.
+func (s *Server) FailTransaction(id, reasonCode string) error {
+	return s.finish(id, stateFailed, reasonCode)
+}
+
+// CompletePending completes every pending transaction which is not scheduled and returns their number.
+func (s *Server) CompletePending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, t := range s.transactions {
+		if t.State == statePending && t.ScheduledFor == "" {
+			s.setState(t, stateCompleted, "")
+			n++
+		}
+	}
+
+	return n
+}
+
+func (s *Server) finish(id, state, reasonCode string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.transaction(id)
+	if t == nil {
+		return fmt.Errorf("revoluttest: transaction %s not found", id)
+	}
+	if t.State != statePending {
+		return fmt.Errorf("revoluttest: transaction %s is %s", id, t.State)
+	}
+	s.setState(t, state, reasonCode)
+
+	return nil
+}
+
+// setState moves t to state, refunds a transaction which did not complete and notifies the web-hook.
+// The caller must hold s.mu.
+func (s *Server) setState(t *transaction, state, reasonCode string) {
+	old := t.State
+	now := s.now()
+
+	t.State = state
+	t.ReasonCode = reasonCode
+	t.UpdatedAt = now
+	if state == stateCompleted {
+		t.CompletedAt = &now
+	}
+	if state == stateDeclined || state == stateFailed {
+		for _, l := range t.Legs {
+			if a := s.account(l.AccountId); a != nil {
+				a.Balance = a.Balance.Sub(l.Amount)
+				a.UpdatedAt = now
+			}
+		}
+	}
+
+	s.notify(s.webhookUrl, map[string]interface{}{
+		"event":     "TransactionStateChanged",
+		"timestamp": now,
+		"data":      map[string]string{"id": t.Id, "old_state": old, "new_state": state},
+	})
+}
+
+// now returns the current time with the precision of the API, it never returns the same time twice
+// so that the transactions are strictly ordered. The caller must hold s.mu.
+func (s *Server) now() time.Time {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	if !now.After(s.lastTime) {
+		now = s.lastTime.Add(time.Millisecond)
+	}
+	s.lastTime = now
+
+	return now
+}
+
+func (s *Server) account(id string) *account {
+	for _, a := range s.accounts {
+		if a.Id == id {
+			return a
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) counterparty(id string) *counterparty {
+	for _, c := range s.counterparties {
+		if c.Id == id {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) transaction(id string) *transaction {
+	for _, t := range s.transactions {
+		if t.Id == id {
+			return t
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) transactionWithRequestId(requestId string) *transaction {
+	for _, t := range s.transactions {
+		if t.RequestId != "" && t.RequestId == requestId {
+			return t
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) routeBusiness(w http.ResponseWriter, r *http.Request, seg []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	route := r.Method + " " + seg[0]
+	if len(seg) > 1 {
+		route += "/{id}"
+	}
+	if len(seg) > 2 {
+		route += "/" + seg[2]
+	}
+
+	switch route {
+	case "GET accounts":
+		writeJSON(w, http.StatusOK, s.accounts)
+	case "GET accounts/{id}":
+		if a := s.account(seg[1]); a != nil {
+			writeJSON(w, http.StatusOK, a)
+			return
+		}
+		writeBusinessError(w, http.StatusNotFound, 3000, "Account not found")
+	case "GET accounts/{id}/bank-details":
+		s.bankDetails(w, seg[1])
+	case "POST counterparty":
+		s.addCounterparty(w, r)
+	case "GET counterparties":
+		writeJSON(w, http.StatusOK, s.counterparties)
+	case "GET counterparty/{id}":
+		if c := s.counterparty(seg[1]); c != nil {
+			writeJSON(w, http.StatusOK, c)
+			return
+		}
+		writeBusinessError(w, http.StatusNotFound, 3000, "Counterparty not found")
+	case "DELETE counterparty/{id}":
+		for i, c := range s.counterparties {
+			if c.Id == seg[1] {
+				s.counterparties = append(s.counterparties[:i], s.counterparties[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		writeBusinessError(w, http.StatusNotFound, 3000, "Counterparty not found")
+	case "POST pay":
+		s.pay(w, r)
+	case "GET transactions":
+		s.listTransactions(w, r)
+	case "GET transaction/{id}":
+		t := s.transaction(seg[1])
+		if r.URL.Query().Get("id_type") == "request_id" {
+			t = s.transactionWithRequestId(seg[1])
+		}
+		if t != nil {
+			writeJSON(w, http.StatusOK, t)
+			return
+		}
+		writeBusinessError(w, http.StatusNotFound, 3000, "Transaction not found")
+	case "DELETE transaction/{id}":
+		t := s.transaction(seg[1])
+		if t == nil {
+			writeBusinessError(w, http.StatusNotFound, 3000, "Transaction not found")
+			return
+		}
+		if t.State != statePending {
+			writeBusinessError(w, http.StatusBadRequest, 3003, "Transaction cannot be cancelled in the "+t.State+" state")
+			return
+		}
+		s.setState(t, stateDeclined, "cancelled")
+		w.WriteHeader(http.StatusNoContent)
+	case "POST transfer":
+		s.transfer(w, r)
+	case "GET rate":
+		s.rate(w, r)
+	case "POST exchange":
+		s.exchange(w, r)
+	case "POST payment-drafts":
+		s.addPaymentDraft(w, r)
+	case "GET payment-drafts":
+		orders := []map[string]interface{}{}
+		for _, d := range s.drafts {
+			orders = append(orders, map[string]interface{}{
+				"id": d.Id, "scheduled_for": d.ScheduledFor, "title": d.Title, "payments_count": len(d.Payments),
+			})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"payment_orders": orders})
+	case "GET payment-drafts/{id}":
+		for _, d := range s.drafts {
+			if d.Id == seg[1] {
+				writeJSON(w, http.StatusOK, d)
+				return
+			}
+		}
+		writeBusinessError(w, http.StatusNotFound, 3000, "Payment draft not found")
+	case "DELETE payment-drafts/{id}":
+		for i, d := range s.drafts {
+			if d.Id == seg[1] {
+				s.drafts = append(s.drafts[:i], s.drafts[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		writeBusinessError(w, http.StatusNotFound, 3000, "Payment draft not found")
+	case "POST webhook":
+		var req struct {
+			Url string `json:"url"`
+		}
+		if !decode(w, r, &req) {
+			return
+		}
+		if req.Url == "" {
+			writeBusinessError(w, http.StatusBadRequest, 3000, "url is required")
+			return
+		}
+		s.webhookUrl = req.Url
+		w.WriteHeader(http.StatusNoContent)
+	case "DELETE webhook":
+		s.webhookUrl = ""
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeBusinessError(w, http.StatusNotFound, 3000, "Not found")
+	}
+}
+
+func (s *Server) bankDetails(w http.ResponseWriter, id string) {
+	a := s.account(id)
+	if a == nil {
+		writeBusinessError(w, http.StatusNotFound, 3000, "Account not found")
+		return
+	}
+
+	d := map[string]interface{}{
+		"beneficiary":         "Revoluttest Ltd",
+		"beneficiary_address": map[string]string{"street_line1": "1 Test Street", "city": "London", "country": "GB", "postcode": "E1 1AA"},
+		"bank_country":        "GB",
+		"pooled":              false,
+	}
+	switch a.Currency {
+	case "GBP":
+		d["account_no"] = "12345678"
+		d["sort_code"] = "040004"
+		d["schemes"] = []string{"faster_payments", "bacs", "chaps"}
+		d["estimated_time"] = map[string]interface{}{"unit": "hours", "min": 0, "max": 2}
+	case "USD":
+		d["account_no"] = "123456789"
+		d["routing_number"] = "026073150"
+		d["schemes"] = []string{"ach"}
+		d["estimated_time"] = map[string]interface{}{"unit": "days", "min": 1, "max": 3}
+	default:
+		d["iban"] = "GB00REVO00996912345678"
+		d["bic"] = "REVOGB21"
+		d["schemes"] = []string{"sepa", "swift"}
+		d["estimated_time"] = map[string]interface{}{"unit": "days", "min": 1, "max": 2}
+	}
+
+	writeJSON(w, http.StatusOK, []interface{}{d})
+}
+
+func (s *Server) addCounterparty(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ProfileType    string `json:"profile_type"`
+		Name           string `json:"name"`
+		Phone          string `json:"phone"`
+		Email          string `json:"email"`
+		CompanyName    string `json:"company_name"`
+		IndividualName *name  `json:"individual_name"`
+		BankCountry    string `json:"bank_country"`
+		Currency       string `json:"currency"`
+		AccountNo      string `json:"account_no"`
+		SortCode       string `json:"sort_code"`
+		RoutingNumber  string `json:"routing_number"`
+		Iban           string `json:"iban"`
+		Bic            string `json:"bic"`
+		Clabe          string `json:"clabe"`
+		Ifsc           string `json:"ifsc"`
+		BsbCode        string `json:"bsb_code"`
+		Address        struct {
+			Country string `json:"country"`
+		} `json:"address"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	now := s.now()
+	c := &counterparty{
+		Id:        newId(),
+		Phone:     req.Phone,
+		State:     "created",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if req.ProfileType != "" {
+		// a Revolut counterparty is identified by its phone or e-mail
+		if req.Phone == "" && req.Email == "" {
+			writeBusinessError(w, http.StatusBadRequest, 3000, "phone or email is required")
+			return
+		}
+		c.Name = req.Name
+		c.ProfileType = req.ProfileType
+		for _, currency := range []string{"GBP", "EUR", "USD"} {
+			c.Accounts = append(c.Accounts, counterpartyAccount{Id: newId(), Currency: currency, Type: "revolut", Name: req.Name, Email: req.Email})
+		}
+	} else {
+		switch {
+		case req.CompanyName != "":
+			c.Name = req.CompanyName
+		case req.IndividualName != nil && req.IndividualName.FirstName != "" && req.IndividualName.LastName != "":
+			c.Name = req.IndividualName.FirstName + " " + req.IndividualName.LastName
+		default:
+			writeBusinessError(w, http.StatusBadRequest, 3000, "company_name or individual_name is required")
+			return
+		}
+		if req.Currency == "" || req.BankCountry == "" {
+			writeBusinessError(w, http.StatusBadRequest, 3000, "currency and bank_country are required")
+			return
+		}
+		if req.AccountNo == "" && req.Iban == "" && req.Clabe == "" {
+			writeBusinessError(w, http.StatusBadRequest, 3000, "account_no, iban or clabe is required")
+			return
+		}
+		c.Country = req.BankCountry
+		if req.Address.Country != "" {
+			c.Country = req.Address.Country
+		}
+		c.Accounts = []counterpartyAccount{{
+			Id:               newId(),
+			Currency:         req.Currency,
+			Type:             "external",
+			AccountNo:        req.AccountNo,
+			Iban:             req.Iban,
+			SortCode:         req.SortCode,
+			RoutingNumber:    req.RoutingNumber,
+			Bic:              req.Bic,
+			Clabe:            req.Clabe,
+			Ifsc:             req.Ifsc,
+			BsbCode:          req.BsbCode,
+			Email:            req.Email,
+			Name:             c.Name,
+			BankCountry:      req.BankCountry,
+			RecipientCharges: "no",
+		}}
+	}
+
+	s.counterparties = append(s.counterparties, c)
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) pay(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RequestId string `json:"request_id"`
+		AccountId string `json:"account_id"`
+		Receiver  struct {
+			CounterpartyId string `json:"counterparty_id"`
+			AccountId      string `json:"account_id"`
+		} `json:"receiver"`
+		Amount      money.Decimal `json:"amount"`
+		Currency    string        `json:"currency"`
+		Reference   string        `json:"reference"`
+		ScheduleFor string        `json:"schedule_for"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if req.RequestId == "" || len(req.RequestId) > 40 {
+		writeBusinessError(w, http.StatusBadRequest, 3000, "request_id is required and must have at most 40 characters")
+		return
+	}
+	// the same request_id never makes a second payment
+	if t := s.transactionWithRequestId(req.RequestId); t != nil {
+		writeJSON(w, http.StatusOK, t)
+		return
+	}
+
+	a := s.account(req.AccountId)
+	if a == nil {
+		writeBusinessError(w, http.StatusNotFound, 3000, "Account not found")
+		return
+	}
+	c := s.counterparty(req.Receiver.CounterpartyId)
+	if c == nil {
+		writeBusinessError(w, http.StatusNotFound, 3000, "Counterparty not found")
+		return
+	}
+	var ca *counterpartyAccount
+	for i := range c.Accounts {
+		if c.Accounts[i].Id == req.Receiver.AccountId || req.Receiver.AccountId == "" && c.Accounts[i].Currency == req.Currency {
+			ca = &c.Accounts[i]
+			break
+		}
+	}
+	if ca == nil {
+		writeBusinessError(w, http.StatusBadRequest, 3000, "The receiver has no account in "+req.Currency)
+		return
+	}
+	if req.Currency != a.Currency || req.Currency != ca.Currency {
+		writeBusinessError(w, http.StatusBadRequest, 3000, "The currency does not match the accounts")
+		return
+	}
+	if !s.debitable(w, a, req.Amount) {
+		return
+	}
+
+	t := s.newTransaction("transfer", req.RequestId, req.Reference)
+	t.ScheduledFor = req.ScheduleFor
+	s.addLeg(t, a, req.Amount.Neg(), &legCounterparty{Id: c.Id, Type: ca.Type, AccountId: ca.Id}, "To "+c.Name)
+	s.created(t)
+
+	// payments to Revolut counterparties complete right away, the others stay pending
+	if ca.Type == "revolut" && req.ScheduleFor == "" {
+		s.setState(t, stateCompleted, "")
+	} else if s.processingDelay > 0 && req.ScheduleFor == "" {
+		time.AfterFunc(s.processingDelay, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			if t.State == statePending {
+				s.setState(t, stateCompleted, "")
+			}
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"id": t.Id, "state": t.State, "created_at": t.CreatedAt, "completed_at": t.CompletedAt})
+}
+
+func (s *Server) transfer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RequestId       string        `json:"request_id"`
+		SourceAccountId string        `json:"source_account_id"`
+		TargetAccountId string        `json:"target_account_id"`
+		Amount          money.Decimal `json:"amount"`
+		Currency        string        `json:"currency"`
+		Reference       string        `json:"reference"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if req.RequestId == "" || len(req.RequestId) > 40 {
+		writeBusinessError(w, http.StatusBadRequest, 3000, "request_id is required and must have at most 40 characters")
+		return
+	}
+	if t := s.transactionWithRequestId(req.RequestId); t != nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"id": t.Id, "state": t.State, "created_at": t.CreatedAt, "completed_at": t.CompletedAt})
+		return
+	}
+
+	source, target := s.account(req.SourceAccountId), s.account(req.TargetAccountId)
+	if source == nil || target == nil {
+		writeBusinessError(w, http.StatusNotFound, 3000, "Account not found")
+		return
+	}
+	if source == target || source.Currency != req.Currency || target.Currency != req.Currency {
+		writeBusinessError(w, http.StatusBadRequest, 3000, "The transfer must be between two different accounts in "+req.Currency)
+		return
+	}
+	if !s.debitable(w, source, req.Amount) {
+		return
+	}
+
+	t := s.newTransaction("transfer", req.RequestId, req.Reference)
+	s.addLeg(t, source, req.Amount.Neg(), &legCounterparty{Type: "self", AccountId: target.Id}, "To "+target.Name)
+	s.addLeg(t, target, req.Amount, &legCounterparty{Type: "self", AccountId: source.Id}, "From "+source.Name)
+	s.created(t)
+	s.setState(t, stateCompleted, "")
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"id": t.Id, "state": t.State, "created_at": t.CreatedAt, "completed_at": t.CompletedAt})
+}
+
+func (s *Server) rate(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	from, to := q.Get("from"), q.Get("to")
+	rate, ok := s.rates[from+"/"+to]
+	if !ok {
+		writeBusinessError(w, http.StatusBadRequest, 3000, "The exchange from "+from+" to "+to+" is not supported")
+		return
+	}
+	a := money.NewFromInt(1)
+	if q.Get("amount") != "" {
+		var err error
+		if a, err = money.Parse(q.Get("amount")); err != nil {
+			writeBusinessError(w, http.StatusBadRequest, 3000, "amount is invalid")
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"from":      amount{Amount: a, Currency: from},
+		"to":        amount{Amount: multiply(a, rate, to), Currency: to},
+		"rate":      rate,
+		"fee":       amount{Currency: from},
+		"rate_date": s.now(),
+	})
+}
+
+func (s *Server) exchange(w http.ResponseWriter, r *http.Request) {
+	type side struct {
+		AccountId string        `json:"account_id"`
+		Currency  string        `json:"currency"`
+		Amount    money.Decimal `json:"amount"`
+	}
+	var req struct {
+		From      side   `json:"from"`
+		To        side   `json:"to"`
+		Reference string `json:"reference"`
+		RequestId string `json:"request_id"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	if req.RequestId == "" || len(req.RequestId) > 40 {
+		writeBusinessError(w, http.StatusBadRequest, 3000, "request_id is required and must have at most 40 characters")
+		return
+	}
+	if t := s.transactionWithRequestId(req.RequestId); t != nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"id": t.Id, "state": t.State, "created_at": t.CreatedAt, "completed_at": t.CompletedAt})
+		return
+	}
+
+	source, target := s.account(req.From.AccountId), s.account(req.To.AccountId)
+	if source == nil || target == nil {
+		writeBusinessError(w, http.StatusNotFound, 3000, "Account not found")
+		return
+	}
+	if source.Currency != req.From.Currency || target.Currency != req.To.Currency {
+		writeBusinessError(w, http.StatusBadRequest, 3000, "The currency does not match the accounts")
+		return
+	}
+	rate, ok := s.rates[req.From.Currency+"/"+req.To.Currency]
+	if !ok {
+		writeBusinessError(w, http.StatusBadRequest, 3000, "The exchange from "+req.From.Currency+" to "+req.To.Currency+" is not supported")
+		return
+	}
+	if req.From.Amount.IsZero() == req.To.Amount.IsZero() {
+		writeBusinessError(w, http.StatusBadRequest, 3000, "Exactly one of from.amount and to.amount is required")
+		return
+	}
+	sold, bought := req.From.Amount, req.To.Amount
+	if sold.IsZero() {
+		sold = divide(bought, rate, source.Currency)
+	} else {
+		bought = multiply(sold, rate, target.Currency)
+	}
+	if !s.debitable(w, source, sold) {
+		return
+	}
+
+	t := s.newTransaction("exchange", req.RequestId, req.Reference)
+	s.addLeg(t, source, sold.Neg(), nil, "Exchanged to "+target.Currency)
+	s.addLeg(t, target, bought, nil, "Exchanged from "+source.Currency)
+	s.created(t)
+	s.setState(t, stateCompleted, "")
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"id": t.Id, "state": t.State, "created_at": t.CreatedAt, "completed_at": t.CompletedAt})
+}
+
+func (s *Server) listTransactions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var from, to time.Time
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		if v := q.Get(p.name); v != "" {
+			t, err := parseTime(v)
+			if err != nil {
+				writeBusinessError(w, http.StatusBadRequest, 3000, p.name+" is invalid")
+				return
+			}
+			*p.t = t
+		}
+	}
+	count := 100
+	if v := q.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 1000 {
+			writeBusinessError(w, http.StatusBadRequest, 3000, "count must be between 1 and 1000")
+			return
+		}
+		count = n
+	}
+
+	r2 := []*transaction{}
+	for _, t := range s.transactions {
+		if !from.IsZero() && t.CreatedAt.Before(from) || !to.IsZero() && !t.CreatedAt.Before(to) {
+			continue
+		}
+		if v := q.Get("type"); v != "" && t.Type != v {
+			continue
+		}
+		if v := q.Get("counterparty"); v != "" && !hasCounterparty(t, v) {
+			continue
+		}
+		r2 = append(r2, t)
+	}
+	// the newest transactions first, a next page is requested with to set to created_at of the last one
+	sort.SliceStable(r2, func(i, j int) bool {
+		return r2[i].CreatedAt.After(r2[j].CreatedAt)
+	})
+	if len(r2) > count {
+		r2 = r2[:count]
+	}
+
+	writeJSON(w, http.StatusOK, r2)
+}
+
+func (s *Server) addPaymentDraft(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title       string `json:"title"`
+		ScheduleFor string `json:"schedule_for"`
+		Payments    []struct {
+			Currency  string        `json:"currency"`
+			Amount    money.Decimal `json:"amount"`
+			AccountId string        `json:"account_id"`
+			Receiver  struct {
+				CounterpartyId string `json:"counterparty_id"`
+				AccountId      string `json:"account_id"`
+			} `json:"receiver"`
+			Reference string `json:"reference"`
+		} `json:"payments"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+	if len(req.Payments) == 0 {
+		writeBusinessError(w, http.StatusBadRequest, 3000, "payments are required")
+		return
+	}
+
+	d := &paymentDraft{Id: newId(), Title: req.Title, ScheduledFor: req.ScheduleFor}
+	for _, p := range req.Payments {
+		if s.account(p.AccountId) == nil {
+			writeBusinessError(w, http.StatusNotFound, 3000, "Account not found")
+			return
+		}
+		if s.counterparty(p.Receiver.CounterpartyId) == nil {
+			writeBusinessError(w, http.StatusNotFound, 3000, "Counterparty not found")
+			return
+		}
+		dp := paymentDraftPayment{
+			Id:        newId(),
+			Amount:    amount{Amount: p.Amount, Currency: p.Currency},
+			Currency:  p.Currency,
+			Account:   p.AccountId,
+			State:     "CREATED",
+			Reference: p.Reference,
+		}
+		dp.Receiver.CounterpartyId = p.Receiver.CounterpartyId
+		dp.Receiver.AccountId = p.Receiver.AccountId
+		d.Payments = append(d.Payments, dp)
+	}
+
+	s.drafts = append(s.drafts, d)
+	writeJSON(w, http.StatusOK, map[string]string{"id": d.Id})
+}
+
+// debitable reports whether amount can be taken from a, it writes the error response when it cannot.
+func (s *Server) debitable(w http.ResponseWriter, a *account, amount money.Decimal) bool {
+	if amount.Sign() <= 0 {
+		writeBusinessError(w, http.StatusBadRequest, 3000, "amount must be positive")
+		return false
+	}
+	if a.State != "active" {
+		writeBusinessError(w, http.StatusBadRequest, 3000, "Account is not active")
+		return false
+	}
+	if a.Balance.Cmp(amount) < 0 {
+		writeBusinessError(w, http.StatusUnprocessableEntity, 3000, "Insufficient balance")
+		return false
+	}
+
+	return true
+}
+
+// newTransaction adds a pending transaction without legs, the caller must hold s.mu.
+func (s *Server) newTransaction(typ, requestId, reference string) *transaction {
+	now := s.now()
+	t := &transaction{
+		Id:        newId(),
+		Type:      typ,
+		RequestId: requestId,
+		State:     statePending,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Reference: reference,
+	}
+	s.transactions = append(s.transactions, t)
+
+	return t
+}
+
+// addLeg moves amount to or from a and records it in t, the caller must hold s.mu.
+func (s *Server) addLeg(t *transaction, a *account, amount money.Decimal, c *legCounterparty, description string) {
+	a.Balance = a.Balance.Add(amount)
+	a.UpdatedAt = t.CreatedAt
+
+	t.Legs = append(t.Legs, leg{
+		LegId:        newId(),
+		AccountId:    a.Id,
+		Counterparty: c,
+		Amount:       amount,
+		Currency:     a.Currency,
+		Description:  description,
+		Balance:      a.Balance,
+	})
+}
+
+// created notifies the web-hook about t once its legs are added, the caller must hold s.mu.
+func (s *Server) created(t *transaction) {
+	s.notify(s.webhookUrl, map[string]interface{}{
+		"event":     "TransactionCreated",
+		"timestamp": t.CreatedAt,
+		"data":      t,
+	})
+}
+
+// multiply converts amount with rate, the result is rounded to the minor units of currency.
+func multiply(amount money.Decimal, rate float64, currency string) money.Decimal {
+	return amount.Mul(decimalRate(rate)).Round(money.Currency(currency).MinorUnits())
+}
+
+// divide converts amount back with rate, the result is rounded to the minor units of currency.
+func divide(amount money.Decimal, rate float64, currency string) money.Decimal {
+	q, _ := new(big.Rat).SetString(amount.String())
+	r, _ := new(big.Rat).SetString(decimalRate(rate).String())
+	if r.Sign() == 0 {
+		return money.Decimal{}
+	}
+
+	return money.MustParse(q.Quo(q, r).FloatString(money.Currency(currency).MinorUnits()))
+}
+
+// decimalRate returns the shortest decimal representation of rate, e.g. exactly 1.11.
+func decimalRate(rate float64) money.Decimal {
+	d, _ := money.NewFromFloat(rate)
+
+	return d
+}
+
+func hasCounterparty(t *transaction, id string) bool {
+	for _, l := range t.Legs {
+		if l.Counterparty != nil && l.Counterparty.Id == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseTime parses a date or a date and time as accepted by the API.
+func parseTime(v string) (time.Time, error) {
+	if !strings.Contains(v, "T") {
+		return time.Parse("2006-01-02", v)
+	}
+
+	return time.Parse(time.RFC3339Nano, v)
+}
+
+// decode reads the JSON body of r into v, it writes the error response when the body is invalid.
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeBusinessError(w, http.StatusBadRequest, 3000, "Invalid request body: "+err.Error())
+		return false
+	}
+
+	return true
+}
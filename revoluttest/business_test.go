@@ -0,0 +1,318 @@
+package revoluttest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	business "github.com/rysavyvladan/go-revolut/business/1.0"
+	"github.com/rysavyvladan/go-revolut/money"
+)
+
+// payee returns the ID of the GBP account and a Revolut counterparty with a GBP account.
+func payee(t *testing.T, bC *business.Client) (string, *business.CounterpartyResp) {
+	t.Helper()
+
+	accounts, err := bC.Account().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gbp := ""
+	for _, a := range accounts {
+		if a.Currency == "GBP" {
+			gbp = a.Id
+		}
+	}
+	cp, err := bC.Counterparty().AddRevolut(&business.RevolutCounterpartyReq{ProfileType: business.CounterpartyProfileType_PERSONAL, Name: "John Smith", Phone: "+4412345678"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return gbp, cp
+}
+
+func paymentReq(accountId string, cp *business.CounterpartyResp, requestId string) *business.PaymentReq {
+	return &business.PaymentReq{
+		RequestId: requestId,
+		AccountId: accountId,
+		Receiver:  business.PaymentReceiver{CounterpartyId: cp.Id, AccountId: cp.Accounts[0].Id},
+		Amount:    money.MustParse("25"),
+		Currency:  "GBP",
+	}
+}
+
+func TestRequestIdDeduplication(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv)
+	gbp, cp := payee(t, bC)
+
+	first, err := bC.Payment().Create(paymentReq(gbp, cp, "invoice-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := bC.Payment().Create(paymentReq(gbp, cp, "invoice-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Id != second.Id {
+		t.Errorf("the same request ID made the transactions %s and %s", first.Id, second.Id)
+	}
+	if b := srv.Balance(gbp); !b.Equal(money.MustParse("9975")) {
+		t.Errorf("got the balance %v, want 9975", b)
+	}
+
+	// Create generates a request ID when it is missing
+	req := paymentReq(gbp, cp, "")
+	if _, err := bC.Payment().Create(req); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.RequestId) != 32 {
+		t.Errorf("got the generated request ID %q, want 32 characters", req.RequestId)
+	}
+}
+
+func TestLookupBeforeResend(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault Fault
+	}{
+		{"dropped after processing", Fault{Drop: true, AfterProcessing: true}},
+		{"503 after processing", Fault{StatusCode: http.StatusServiceUnavailable, AfterProcessing: true}},
+		{"dropped before processing", Fault{Drop: true}},
+		{"503 before processing", Fault{StatusCode: http.StatusServiceUnavailable}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewServer()
+			defer srv.Close()
+			bC := newBusinessClient(t, srv, business.WithRetry(fastRetry))
+			gbp, cp := payee(t, bC)
+
+			f := tt.fault
+			f.Method, f.Path, f.Times = http.MethodPost, "/api/1.0/pay", 1
+			srv.InjectFault(f)
+			resp, err := bC.Payment().Create(paymentReq(gbp, cp, "invoice-1"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Id == "" {
+				t.Error("got no transaction ID")
+			}
+			if b := srv.Balance(gbp); !b.Equal(money.MustParse("9975")) {
+				t.Errorf("got the balance %v, want 9975 after a single payment", b)
+			}
+			if n := count(srv, http.MethodGet, "/api/1.0/transaction/invoice-1"); n != 1 {
+				t.Errorf("got %d lookups, want 1", n)
+			}
+		})
+	}
+}
+
+func TestFailedLookupIsNotResent(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv, business.WithRetry(fastRetry))
+	gbp, cp := payee(t, bC)
+
+	srv.InjectFault(Fault{Method: http.MethodPost, Path: "/api/1.0/pay", Drop: true, Times: 1})
+	srv.InjectFault(Fault{Method: http.MethodGet, Path: "/api/1.0/transaction/*"})
+	if _, err := bC.Payment().Create(paymentReq(gbp, cp, "invoice-1")); err == nil {
+		t.Fatal("got no error when the outcome is unknown")
+	}
+	if n := count(srv, http.MethodPost, "/api/1.0/pay"); n != 1 {
+		t.Errorf("got %d payment requests, want 1", n)
+	}
+}
+
+func TestRequestIdTooLong(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv)
+	gbp, cp := payee(t, bC)
+
+	_, err := bC.Payment().Create(paymentReq(gbp, cp, "invoice-0123456789-0123456789-0123456789x"))
+	if !business.IsValidation(err) {
+		t.Errorf("got %v, want a ValidationError", err)
+	}
+	if n := count(srv, http.MethodPost, "/api/1.0/pay"); n != 0 {
+		t.Errorf("got %d payment requests, want none", n)
+	}
+}
+
+func TestWithRequestIdEscaping(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv)
+	gbp, cp := payee(t, bC)
+
+	for _, id := range []string{"invoice/2021/1", "invoice?id=2", "invoice#3", "invoice%2F4", "invoice 5"} {
+		created, err := bC.Payment().Create(paymentReq(gbp, cp, id))
+		if err != nil {
+			t.Fatalf("%q: %v", id, err)
+		}
+		found, err := bC.Payment().WithRequestId(id)
+		if err != nil {
+			t.Errorf("%q: %v", id, err)
+			continue
+		}
+		if found.Id != created.Id {
+			t.Errorf("%q: got the transaction %s, want %s", id, found.Id, created.Id)
+		}
+	}
+}
+
+func TestWaitForFinalState(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SetProcessingDelay(50 * time.Millisecond)
+	bC := newBusinessClient(t, srv)
+	gbp, _ := payee(t, bC)
+
+	cp, err := bC.Counterparty().AddNonRevolut(&business.NonRevolutCounterpartyReq{
+		CompanyName: "Acme Ltd", BankCountry: "GB", Currency: "GBP", AccountNo: "31926819", SortCode: "601613",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payment, err := bC.Payment().Create(paymentReq(gbp, cp, "invoice-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payment.State != business.PaymentState_PENDING {
+		t.Fatalf("got the state %s, want pending", payment.State)
+	}
+
+	final, err := bC.Payment().WaitForFinalState(payment.Id, &business.WaitOptions{MinInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if final.State != business.PaymentState_COMPLETE {
+		t.Errorf("got the state %s, want completed", final.State)
+	}
+}
+
+func TestAddNonRevolutCompactsIdentifiers(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv)
+
+	tests := []struct {
+		req  *business.NonRevolutCounterpartyReq
+		want business.CounterpartyRespAccount
+	}{
+		{
+			&business.NonRevolutCounterpartyReq{CompanyName: "Acme Ltd", BankCountry: "GB", Currency: "GBP", AccountNo: "3192 6819", SortCode: "60-16-13"},
+			business.CounterpartyRespAccount{AccountNo: "31926819", SortCode: "601613"},
+		},
+		{
+			&business.NonRevolutCounterpartyReq{CompanyName: "Acme GmbH", BankCountry: "DE", Currency: "EUR", Iban: "de89 3704 0044 0532 0130 00", Bic: "cobade ffxxx"},
+			business.CounterpartyRespAccount{Iban: "DE89370400440532013000", Bic: "COBADEFFXXX"},
+		},
+	}
+	for _, tt := range tests {
+		orig := *tt.req
+		cp, err := bC.Counterparty().AddNonRevolut(tt.req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a := cp.Accounts[0]
+		if a.AccountNo != tt.want.AccountNo || a.SortCode != tt.want.SortCode || a.Iban != tt.want.Iban || a.Bic != tt.want.Bic {
+			t.Errorf("%s: got the account %+v, want %+v", tt.req.CompanyName, a, tt.want)
+		}
+		if *tt.req != orig {
+			t.Errorf("%s: the request was modified", tt.req.CompanyName)
+		}
+	}
+}
+
+func TestWaitForFinalStateTransientErrors(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SetProcessingDelay(100 * time.Millisecond)
+	bC := newBusinessClient(t, srv)
+	gbp, _ := payee(t, bC)
+
+	cp, err := bC.Counterparty().AddNonRevolut(&business.NonRevolutCounterpartyReq{
+		CompanyName: "Acme Ltd", BankCountry: "GB", Currency: "GBP", AccountNo: "31926819", SortCode: "601613",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payment, err := bC.Payment().Create(paymentReq(gbp, cp, "invoice-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &business.WaitOptions{MinInterval: 10 * time.Millisecond, MaxInterval: 20 * time.Millisecond}
+
+	// a transaction not visible yet, a failing server and a lost connection do not end the wait
+	path := "/api/1.0/transaction/" + payment.Id
+	srv.InjectFault(Fault{Method: http.MethodGet, Path: path, Times: 1, StatusCode: http.StatusNotFound, Body: `{"code":3001,"message":"Transaction not found"}`})
+	srv.InjectFault(Fault{Method: http.MethodGet, Path: path, Times: 2, StatusCode: http.StatusInternalServerError})
+	srv.InjectFault(Fault{Method: http.MethodGet, Path: path, Times: 1, Drop: true})
+	final, err := bC.Payment().WaitForFinalState(payment.Id, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if final.State != business.PaymentState_COMPLETE {
+		t.Errorf("got the state %s, want completed", final.State)
+	}
+
+	// the other errors are returned
+	srv.InjectFault(Fault{Method: http.MethodGet, Path: path, Times: 1, StatusCode: http.StatusBadRequest, Body: `{"code":3000,"message":"Bad request"}`})
+	if _, err := bC.Payment().WaitForFinalState(payment.Id, opts); !business.IsValidation(err) {
+		t.Errorf("got %v, want the 400", err)
+	}
+
+	// a transaction which never appears ends the wait with the 404 after the grace period
+	opts.NotFoundGrace = 100 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err = bC.Payment().WaitForFinalStateWithContext(ctx, "00000000-0000-0000-0000-000000000000", opts)
+	if !business.IsNotFound(err) {
+		t.Errorf("got %v, want the 404", err)
+	}
+	if d := time.Since(start); d < opts.NotFoundGrace {
+		t.Errorf("the wait ended after %v, before the grace period of %v", d, opts.NotFoundGrace)
+	}
+}
+
+func TestExchangeBalances(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv)
+
+	accounts, err := bC.Account().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[money.Currency]string{}
+	for _, a := range accounts {
+		ids[a.Currency] = a.Id
+	}
+
+	// the sold amount, 0.1 GBP at 1.11 is 0.111 EUR rounded to 0.11
+	sold := money.MustParse("0.1")
+	if _, err := bC.Exchange().Exchange(&business.ExchangeReq{
+		From: business.ExchangeAmount{AccountId: ids["GBP"], Currency: "GBP", Amount: &sold},
+		To:   business.ExchangeAmount{AccountId: ids["EUR"], Currency: "EUR"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// the bought amount, 10 GBP at 0.775 costs 12.903225... rounded to 12.90 USD
+	bought := money.MustParse("10")
+	if _, err := bC.Exchange().Exchange(&business.ExchangeReq{
+		From: business.ExchangeAmount{AccountId: ids["USD"], Currency: "USD"},
+		To:   business.ExchangeAmount{AccountId: ids["GBP"], Currency: "GBP", Amount: &bought},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for c, want := range map[money.Currency]string{"GBP": "10009.9", "EUR": "10000.11", "USD": "9987.1"} {
+		if got := srv.Balance(ids[c]); !got.Equal(money.MustParse(want)) {
+			t.Errorf("got the %s balance %s, want %s", c, got, want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package revoluttest
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	business "github.com/rysavyvladan/go-revolut/business/1.0"
+)
+
+// addCounterparties adds a Revolut counterparty, an external company and an external individual.
+func addCounterparties(t *testing.T, bC *business.Client) {
+	t.Helper()
+
+	if _, err := bC.Counterparty().AddRevolut(&business.RevolutCounterpartyReq{ProfileType: business.CounterpartyProfileType_PERSONAL, Name: "John Smith", Phone: "+4412345678"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bC.Counterparty().AddNonRevolut(&business.NonRevolutCounterpartyReq{CompanyName: "Acme Ltd", BankCountry: "GB", Currency: "GBP", AccountNo: "31926819", SortCode: "60-16-13"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bC.Counterparty().AddNonRevolut(&business.NonRevolutCounterpartyReq{IndividualName: &business.NonRevolutCounterpartyReqIndividualName{FirstName: "Hans", LastName: "Muller"}, BankCountry: "DE", Currency: "EUR", Iban: "DE89370400440532013000", Bic: "COBADEFFXXX"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSyncExportedState(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv)
+	addCounterparties(t, bC)
+
+	var exported bytes.Buffer
+	if err := bC.Counterparty().Export(&exported, business.ExportFormat_CSV); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := bC.Counterparty().PlanSync(bytes.NewReader(exported.Bytes()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.IsEmpty() || len(plan.Invalid()) != 0 {
+		t.Errorf("the exported counterparties are not in sync:\n%s", plan)
+	}
+	if err := bC.Counterparty().ApplySync(plan, false); err != nil {
+		t.Errorf("ApplySync of an empty plan: %v", err)
+	}
+}
+
+func TestSync(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv)
+	addCounterparties(t, bC)
+
+	// John Smith and Hans Muller are kept, Acme Ltd is deleted and Acme Inc is created
+	desired := "type,name,phone,profile_type,company_name,first_name,last_name,bank_country,currency,iban,bic,account_no,routing_number\n" +
+		"revolut,John Smith,+4412345678,personal,,,,,,,,,\n" +
+		"external,,,,,Hans,Muller,DE,EUR,DE89 3704 0044 0532 0130 00,COBADEFFXXX,,\n" +
+		"external,,,,Acme Inc,,,US,USD,,,123456789,021000021\n"
+	plan, err := bC.Counterparty().PlanSync(strings.NewReader(desired), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creates := plan.Creates(); len(creates) != 1 || creates[0].Name() != "Acme Inc" {
+		t.Errorf("got the creates %v, want Acme Inc", creates)
+	}
+	if len(plan.Deletes) != 1 || plan.Deletes[0].Counterparty.Name != "Acme Ltd" {
+		t.Fatalf("got the plan\n%s\nwant to delete Acme Ltd", plan)
+	}
+
+	// nothing is changed without the confirmation of the deletes
+	if err := bC.Counterparty().ApplySync(plan, false); !errors.Is(err, business.ErrDeletesNotConfirmed) {
+		t.Fatalf("got %v, want ErrDeletesNotConfirmed", err)
+	}
+	if counterparties, _ := bC.Counterparty().List(); len(counterparties) != 3 {
+		t.Errorf("got %d counterparties after a refused plan, want 3", len(counterparties))
+	}
+
+	if err := bC.Counterparty().ApplySync(plan, true); err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Deletes[0].Deleted || plan.Creates()[0].Status != business.ImportRowStatus_CREATED {
+		t.Errorf("got the plan\n%s\nwant the changes applied", plan)
+	}
+	counterparties, err := bC.Counterparty().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, c := range counterparties {
+		names[c.Name] = true
+	}
+	if len(counterparties) != 3 || !names["John Smith"] || !names["Hans Muller"] || !names["Acme Inc"] {
+		t.Errorf("got the counterparties %v, want John Smith, Hans Muller and Acme Inc", names)
+	}
+
+	// the desired state is reached
+	plan, err = bC.Counterparty().PlanSync(strings.NewReader(desired), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.IsEmpty() {
+		t.Errorf("got the plan\n%s\nwant no changes", plan)
+	}
+}
+
+func TestSyncInvalidRows(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv)
+	addCounterparties(t, bC)
+
+	// the row of Acme Ltd lacks its sort code, its counterparty would be deleted
+	desired := "type,name,phone,profile_type,company_name,bank_country,currency,account_no\n" +
+		"revolut,John Smith,+4412345678,personal,,,,\n" +
+		"external,,,,Acme Ltd,GB,GBP,31926819\n"
+	plan, err := bC.Counterparty().PlanSync(strings.NewReader(desired), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invalid := plan.Invalid(); len(invalid) != 1 || invalid[0].Line != 3 {
+		t.Fatalf("got the plan\n%s\nwant line 3 invalid", plan)
+	}
+	if err := bC.Counterparty().ApplySync(plan, true); err == nil {
+		t.Error("ApplySync of a plan with invalid rows: got no error")
+	}
+	if counterparties, _ := bC.Counterparty().List(); len(counterparties) != 3 {
+		t.Errorf("got %d counterparties after a refused plan, want 3", len(counterparties))
+	}
+}
+
+func TestImport(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv)
+	addCounterparties(t, bC)
+
+	// a CSV saved by a spreadsheet with the existing counterparties written differently, a row without
+	// its routing number, a new counterparty and its duplicate
+	csv := "\xef\xbb\xbftype;profile_type;name;phone;company_name;first_name;last_name;bank_country;currency;iban;bic;account_no;sort_code\r\n" +
+		"revolut;personal;John Smith;+44 1234 5678;;;;;;;;;\r\n" +
+		";;;;Acme Limited;;;gb;gbp;;;3192 6819;601613\r\n" +
+		"external;;;;;Hans;Muller;DE;EUR;de89 3704 0044 0532 0130 00;COBADEFFXXX;;\r\n" +
+		"external;;;;Acme Inc;;;US;USD;;;123456789;\r\n" +
+		"external;;;;Acme SA;;;FR;EUR;FR1420041010050500013M02606;BNPAFRPP;;\r\n" +
+		"external;;;;Acme SAS;;;FR;EUR;FR14 2004 1010 0505 0001 3M02 606;BNPAFRPP;;\r\n"
+	want := []business.ImportRowStatus{
+		business.ImportRowStatus_EXISTING,
+		business.ImportRowStatus_EXISTING,
+		business.ImportRowStatus_EXISTING,
+		business.ImportRowStatus_INVALID,
+		business.ImportRowStatus_CREATE,
+		business.ImportRowStatus_DUPLICATE,
+	}
+	check := func(rows []*business.ImportRow, want []business.ImportRowStatus) {
+		t.Helper()
+		if len(rows) != len(want) {
+			t.Fatalf("got %d rows, want %d", len(rows), len(want))
+		}
+		for i, row := range rows {
+			if row.Status != want[i] {
+				t.Errorf("line %d: got the status %s (%v), want %s", row.Line, row.Status, row.Err, want[i])
+			}
+		}
+	}
+
+	// a dry run creates nothing
+	rows, err := bC.Counterparty().Import(strings.NewReader(csv), &business.CounterpartyImport{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(rows, want)
+	if rows[5].Err == nil || !strings.Contains(rows[5].Err.Error(), "line 6") {
+		t.Errorf("got %v, want the duplicate of line 6", rows[5].Err)
+	}
+	if counterparties, _ := bC.Counterparty().List(); len(counterparties) != 3 {
+		t.Errorf("got %d counterparties after a dry run, want 3", len(counterparties))
+	}
+
+	want[4] = business.ImportRowStatus_CREATED
+	rows, err = bC.Counterparty().Import(strings.NewReader(csv), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(rows, want)
+	if rows[4].Counterparty == nil || rows[4].Counterparty.Name != "Acme SA" {
+		t.Errorf("got the counterparty %v, want Acme SA", rows[4].Counterparty)
+	}
+	if counterparties, _ := bC.Counterparty().List(); len(counterparties) != 4 {
+		t.Errorf("got %d counterparties, want 4", len(counterparties))
+	}
+
+	// the import is idempotent
+	want[4] = business.ImportRowStatus_EXISTING
+	rows, err = bC.Counterparty().Import(strings.NewReader(csv), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(rows, want)
+}
@@ -0,0 +1,294 @@
+package revoluttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// the states of orders, see merchant.OrderState
+const (
+	orderPending    = "PENDING"
+	orderAuthorised = "AUTHORISED"
+	orderCompleted  = "COMPLETED"
+	orderCancelled  = "CANCELLED"
+	orderFailed     = "FAILED"
+)
+
+type orderAmount struct {
+	Value    int    `json:"value"`
+	Currency string `json:"currency"`
+}
+
+type orderRelated struct {
+	Id     string      `json:"id"`
+	Type   string      `json:"type"`
+	Amount orderAmount `json:"amount"`
+}
+
+type order struct {
+	Id                     string         `json:"id"`
+	PublicId               string         `json:"public_id"`
+	Type                   string         `json:"type"`
+	State                  string         `json:"state"`
+	CreatedDate            int64          `json:"created_date"`
+	UpdatedDate            int64          `json:"updated_date"`
+	CompletedDate          int64          `json:"completed_date,omitempty"`
+	CaptureMode            string         `json:"capture_mode,omitempty"`
+	Description            string         `json:"description,omitempty"`
+	OrderAmount            orderAmount    `json:"order_amount"`
+	OutstandingAmount      orderAmount    `json:"order_outstanding_amount"`
+	RefundedAmount         *orderAmount   `json:"refunded_amount,omitempty"`
+	SettlementCurrency     string         `json:"settlement_currency,omitempty"`
+	MerchantOrderExtRef    string         `json:"merchant_order_ext_ref,omitempty"`
+	MerchantCustomerExtRef string         `json:"merchant_customer_ext_ref,omitempty"`
+	Email                  string         `json:"email,omitempty"`
+	Related                []orderRelated `json:"related,omitempty"`
+}
+
+// PayOrder emulates the customer paying a pending order, it completes an order with the automatic capture mode
+// and authorises an order with the manual one.
+func (s *Server) PayOrder(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.order(id)
+	if o == nil {
+		return fmt.Errorf("revoluttest: order %s not found", id)
+	}
+	if o.State != orderPending {
+		return fmt.Errorf("revoluttest: order %s is %s", id, o.State)
+	}
+
+	if o.CaptureMode == "MANUAL" {
+		s.setOrderState(o, orderAuthorised)
+	} else {
+		s.setOrderState(o, orderCompleted)
+	}
+
+	return nil
+}
+
+// FailOrder emulates a declined payment of a pending order.
+func (s *Server) FailOrder(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := s.order(id)
+	if o == nil {
+		return fmt.Errorf("revoluttest: order %s not found", id)
+	}
+	if o.State != orderPending {
+		return fmt.Errorf("revoluttest: order %s is %s", id, o.State)
+	}
+	s.setOrderState(o, orderFailed)
+
+	return nil
+}
+
+// setOrderState moves o to state and notifies the web-hooks, the caller must hold s.mu.
+func (s *Server) setOrderState(o *order, state string) {
+	now := millis(time.Now())
+
+	o.State = state
+	o.UpdatedDate = now
+	if state == orderCompleted {
+		o.CompletedDate = now
+		o.OutstandingAmount.Value = 0
+	}
+
+	event := ""
+	switch state {
+	case orderAuthorised:
+		event = "ORDER_AUTHORISED"
+	case orderCompleted:
+		event = "ORDER_COMPLETED"
+	default:
+		return
+	}
+	for _, url := range s.merchantWebhooks {
+		s.notify(url, map[string]string{"event": event, "order_id": o.Id})
+	}
+}
+
+func (s *Server) order(id string) *order {
+	for _, o := range s.orders {
+		if o.Id == id {
+			return o
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) routeMerchant(w http.ResponseWriter, r *http.Request, seg []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	route := r.Method + " " + seg[0]
+	if len(seg) > 1 {
+		route += "/{id}"
+	}
+	if len(seg) > 2 {
+		route += "/" + seg[2]
+	}
+
+	switch route {
+	case "POST orders":
+		s.createOrder(w, r)
+	case "GET orders/{id}":
+		if o := s.order(seg[1]); o != nil {
+			writeJSON(w, http.StatusOK, o)
+			return
+		}
+		writeMerchantError(w, http.StatusNotFound, 1007, "Order not found")
+	case "POST orders/{id}/capture":
+		o := s.order(seg[1])
+		if o == nil {
+			writeMerchantError(w, http.StatusNotFound, 1007, "Order not found")
+			return
+		}
+		if o.State != orderAuthorised {
+			writeMerchantError(w, http.StatusBadRequest, 1018, "Order in state "+o.State+" cannot be captured")
+			return
+		}
+		s.setOrderState(o, orderCompleted)
+		writeJSON(w, http.StatusOK, o)
+	case "POST orders/{id}/cancel":
+		o := s.order(seg[1])
+		if o == nil {
+			writeMerchantError(w, http.StatusNotFound, 1007, "Order not found")
+			return
+		}
+		if o.State != orderPending && o.State != orderAuthorised {
+			writeMerchantError(w, http.StatusBadRequest, 1018, "Order in state "+o.State+" cannot be cancelled")
+			return
+		}
+		s.setOrderState(o, orderCancelled)
+		writeJSON(w, http.StatusOK, o)
+	case "POST orders/{id}/refund":
+		s.refundOrder(w, r, seg[1])
+	case "POST webhooks":
+		var req struct {
+			Url string `json:"url"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Url == "" {
+			writeMerchantError(w, http.StatusBadRequest, 1000, "url is required")
+			return
+		}
+		s.merchantWebhooks = []string{req.Url}
+		w.WriteHeader(http.StatusNoContent)
+	case "GET webhooks":
+		urls := []map[string]string{}
+		for _, url := range s.merchantWebhooks {
+			urls = append(urls, map[string]string{"url": url})
+		}
+		writeJSON(w, http.StatusOK, urls)
+	default:
+		writeMerchantError(w, http.StatusNotFound, 1000, "Not found")
+	}
+}
+
+func (s *Server) createOrder(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount             int    `json:"amount"`
+		CaptureMode        string `json:"capture_mode"`
+		MerchantOrderId    string `json:"merchant_order_id"`
+		CustomerEmail      string `json:"customer_email"`
+		Description        string `json:"description"`
+		Currency           string `json:"currency"`
+		SettlementCurrency string `json:"settlement_currency"`
+		MerchantCustomerId string `json:"merchant_customer_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMerchantError(w, http.StatusBadRequest, 1000, "Invalid request body: "+err.Error())
+		return
+	}
+	if req.Amount <= 0 || len(req.Currency) != 3 {
+		writeMerchantError(w, http.StatusBadRequest, 1000, "amount and currency are required")
+		return
+	}
+	if req.CaptureMode == "" {
+		req.CaptureMode = "AUTOMATIC"
+	}
+
+	now := millis(time.Now())
+	o := &order{
+		Id:                     newId(),
+		PublicId:               newId(),
+		Type:                   "PAYMENT",
+		State:                  orderPending,
+		CreatedDate:            now,
+		UpdatedDate:            now,
+		CaptureMode:            req.CaptureMode,
+		Description:            req.Description,
+		OrderAmount:            orderAmount{Value: req.Amount, Currency: req.Currency},
+		OutstandingAmount:      orderAmount{Value: req.Amount, Currency: req.Currency},
+		SettlementCurrency:     req.SettlementCurrency,
+		MerchantOrderExtRef:    req.MerchantOrderId,
+		MerchantCustomerExtRef: req.MerchantCustomerId,
+		Email:                  req.CustomerEmail,
+	}
+	s.orders = append(s.orders, o)
+
+	writeJSON(w, http.StatusCreated, o)
+}
+
+func (s *Server) refundOrder(w http.ResponseWriter, r *http.Request, id string) {
+	var req struct {
+		Amount          int    `json:"amount"`
+		MerchantOrderId string `json:"merchant_order_id"`
+		Description     string `json:"description"`
+		Currency        string `json:"currency"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMerchantError(w, http.StatusBadRequest, 1000, "Invalid request body: "+err.Error())
+		return
+	}
+
+	o := s.order(id)
+	if o == nil {
+		writeMerchantError(w, http.StatusNotFound, 1007, "Order not found")
+		return
+	}
+	if o.State != orderCompleted || o.Type != "PAYMENT" {
+		writeMerchantError(w, http.StatusBadRequest, 1018, "Order in state "+o.State+" cannot be refunded")
+		return
+	}
+	refunded := 0
+	if o.RefundedAmount != nil {
+		refunded = o.RefundedAmount.Value
+	}
+	if req.Amount <= 0 || req.Currency != o.OrderAmount.Currency || refunded+req.Amount > o.OrderAmount.Value {
+		writeMerchantError(w, http.StatusBadRequest, 1000, "The refund exceeds the amount of the order")
+		return
+	}
+
+	now := millis(time.Now())
+	refund := &order{
+		Id:                  newId(),
+		Type:                "REFUND",
+		State:               orderCompleted,
+		CreatedDate:         now,
+		UpdatedDate:         now,
+		CompletedDate:       now,
+		Description:         req.Description,
+		OrderAmount:         orderAmount{Value: req.Amount, Currency: req.Currency},
+		MerchantOrderExtRef: req.MerchantOrderId,
+		Email:               o.Email,
+		Related:             []orderRelated{{Id: o.Id, Type: o.Type, Amount: o.OrderAmount}},
+	}
+	s.orders = append(s.orders, refund)
+
+	o.RefundedAmount = &orderAmount{Value: refunded + req.Amount, Currency: o.OrderAmount.Currency}
+	o.UpdatedDate = now
+	o.Related = append(o.Related, orderRelated{Id: refund.Id, Type: refund.Type, Amount: refund.OrderAmount})
+
+	writeJSON(w, http.StatusCreated, refund)
+}
+
+// millis returns t in milliseconds since the Unix epoch, the format of dates of the Merchant API.
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,316 @@
+package revoluttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the secrets in a cassette.
+const Redacted = "REDACTED"
+
+// Mode selects whether a Recorder records or replays the traffic.
+type Mode int
+
+const (
+	// ModeRecord sends the requests to the API and records them in the cassette
+	ModeRecord Mode = iota
+	// ModeReplay serves the responses of the cassette without sending any request
+	ModeReplay
+)
+
+// ErrUnmatchedRequest is returned by a replaying Recorder for a request not found in the cassette.
+var ErrUnmatchedRequest = errors.New("revoluttest: request not found in the cassette")
+
+// Cassette is the content of a cassette file.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request of an Interaction with its credentials redacted.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Url    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response of an Interaction with its tokens redacted.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper recording the traffic of a client to a cassette file or replaying it,
+// use it with business.WithHTTPClient or merchant.WithHTTPClient:
+//
+//	rec, err := revoluttest.NewRecorder("testdata/accounts.json", revoluttest.ModeReplay)
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer rec.Stop()
+//
+//	bC, err := business.NewClient(clientId, refreshToken, privateKey, issuer, true, business.WithHTTPClient(rec.Client()))
+//
+// Bearer tokens, API keys, client assertions, authorisation codes and access and refresh tokens are redacted
+// before they are recorded. The requests are matched by the method, the path and the query in the recorded order,
+// each interaction is replayed once.
+type Recorder struct {
+	// the transport of the recorded requests, http.DefaultTransport when nil
+	Transport http.RoundTripper
+	// match the request bodies as well, e.g. when the same endpoint is called with different payloads
+	MatchBody bool
+	// an optional function redacting further secrets of an interaction before it is recorded
+	Redact func(*Interaction)
+
+	mode     Mode
+	path     string
+	mu       sync.Mutex
+	cassette Cassette
+	played   []bool
+}
+
+// NewRecorder creates a recorder of the cassette at path, in ModeReplay the cassette must exist.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		mode: mode,
+		path: path,
+	}
+	if mode == ModeReplay {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &r.cassette); err != nil {
+			return nil, fmt.Errorf("revoluttest: invalid cassette %s: %w", path, err)
+		}
+		r.played = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// Client returns an HTTP client using the recorder as its transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip records or replays req.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+
+	return r.record(req, body)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp, err := transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	i := &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Url:    req.URL.String(),
+			Header: req.Header.Clone(),
+			Body:   string(body),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(respBody),
+		},
+	}
+	redact(i)
+	if r.Redact != nil {
+		r.Redact(i)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	// the recorded bodies are redacted, so is the body of req before it is compared
+	want := &Interaction{Request: RecordedRequest{Method: req.Method, Url: req.URL.String(), Header: req.Header.Clone(), Body: string(body)}}
+	redact(want)
+	if r.Redact != nil {
+		r.Redact(want)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for n, i := range r.cassette.Interactions {
+		if r.played[n] || !r.matches(i.Request, want.Request) {
+			continue
+		}
+		r.played[n] = true
+
+		header := i.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(strings.NewReader(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrUnmatchedRequest, req.Method, req.URL)
+}
+
+// matches compares the method, the path and the query of two requests, the host is ignored
+// so that a cassette recorded in the sandbox can be replayed with any environment.
+func (r *Recorder) matches(recorded, req RecordedRequest) bool {
+	if recorded.Method != req.Method {
+		return false
+	}
+	ru, err := url.Parse(recorded.Url)
+	if err != nil {
+		return false
+	}
+	u, err := url.Parse(req.Url)
+	if err != nil {
+		return false
+	}
+	if ru.Path != u.Path || ru.Query().Encode() != u.Query().Encode() {
+		return false
+	}
+
+	return !r.MatchBody || recorded.Body == req.Body
+}
+
+// Unplayed returns the interactions of the cassette which were not replayed yet.
+func (r *Recorder) Unplayed() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unplayed []*Interaction
+	for n, i := range r.cassette.Interactions {
+		if !r.played[n] {
+			unplayed = append(unplayed, i)
+		}
+	}
+
+	return unplayed
+}
+
+// Stop writes the recorded interactions to the cassette, it does nothing in ModeReplay.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.path, b, 0644)
+}
+
+// the form fields and the JSON fields of the token endpoint holding secrets
+var secretFields = []string{"client_assertion", "refresh_token", "code", "access_token"}
+
+// redact replaces the credentials of i with Redacted.
+func redact(i *Interaction) {
+	if v := i.Request.Header.Get("Authorization"); v != "" {
+		i.Request.Header.Set("Authorization", "Bearer "+Redacted)
+	}
+	i.Response.Header.Del("Set-Cookie")
+
+	if strings.HasPrefix(i.Request.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if form, err := url.ParseQuery(i.Request.Body); err == nil {
+			for _, f := range secretFields {
+				if form.Get(f) != "" {
+					form.Set(f, Redacted)
+				}
+			}
+			i.Request.Body = form.Encode()
+		}
+	}
+	i.Response.Body = redactJSON(i.Response.Body)
+}
+
+// redactJSON replaces the secret fields of a JSON object, other bodies are returned unchanged.
+func redactJSON(body string) string {
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &m); err != nil {
+		return body
+	}
+
+	redacted := false
+	for _, f := range secretFields {
+		if _, ok := m[f]; ok {
+			m[f] = Redacted
+			redacted = true
+		}
+	}
+	if !redacted {
+		return body
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return body
+	}
+
+	return string(b)
+}
@@ -0,0 +1,107 @@
+package revoluttest
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	business "github.com/rysavyvladan/go-revolut/business/1.0"
+	merchant "github.com/rysavyvladan/go-revolut/merchant/1.0"
+)
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "revoluttest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return dir
+}
+
+func TestRecorderRedaction(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	cassette := filepath.Join(tempDir(t), "accounts.json")
+
+	rec, err := NewRecorder(cassette, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bC := newBusinessClient(t, srv, business.WithHTTPClient(rec.Client()))
+	if _, err := bC.Account().List(); err != nil {
+		t.Fatal(err)
+	}
+	mC := srv.MerchantClient(merchant.WithHTTPClient(rec.Client()))
+	if _, err := mC.Order().Create(&merchant.OrderReq{Amount: 1000, Currency: "GBP"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{RefreshToken, MerchantApiKey, "oa_revoluttest_", "client_assertion=ey"} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("the cassette contains %q", secret)
+		}
+	}
+	if !strings.Contains(string(b), Redacted) {
+		t.Error("the cassette has no redacted values")
+	}
+}
+
+func TestRecorderReplay(t *testing.T) {
+	srv := NewServer()
+	cassette := filepath.Join(tempDir(t), "accounts.json")
+
+	rec, err := NewRecorder(cassette, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bC := newBusinessClient(t, srv, business.WithHTTPClient(rec.Client()))
+	recorded, err := bC.Account().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	// the replay must not reach the server
+	srv.Close()
+
+	rec, err = NewRecorder(cassette, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bC = newBusinessClient(t, srv, business.WithHTTPClient(rec.Client()))
+	replayed, err := bC.Account().List()
+	if err != nil {
+		t.Fatalf("List from the cassette: %v", err)
+	}
+	if len(replayed) != len(recorded) || replayed[0].Id != recorded[0].Id {
+		t.Errorf("got the accounts %v, want %v", replayed, recorded)
+	}
+	if unplayed := rec.Unplayed(); len(unplayed) != 0 {
+		t.Errorf("got %d unplayed interactions, want none", len(unplayed))
+	}
+
+	// each interaction is replayed once
+	if _, err := bC.Account().List(); !errors.Is(err, ErrUnmatchedRequest) {
+		t.Errorf("got %v, want ErrUnmatchedRequest", err)
+	}
+}
+
+func TestRecorderMissingCassette(t *testing.T) {
+	if _, err := NewRecorder(filepath.Join(tempDir(t), "missing.json"), ModeReplay); err == nil {
+		t.Error("got no error for a missing cassette")
+	}
+}
@@ -0,0 +1,485 @@
+// Package revoluttest provides an in-process fake of the Revolut Business and Merchant APIs for tests.
+//
+// The Server keeps accounts, counterparties, transactions, payment drafts, orders and web-hooks in memory,
+// moves them through the states of the real API and can inject faults into selected requests:
+//
+//	srv := revoluttest.NewServer()
+//	defer srv.Close()
+//
+//	bC, err := srv.BusinessClient()
+//	if err != nil {
+//		panic(err)
+//	}
+//	accounts, err := bC.Account().List()
+package revoluttest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	business "github.com/rysavyvladan/go-revolut/business/1.0"
+	"github.com/rysavyvladan/go-revolut/environment"
+	merchant "github.com/rysavyvladan/go-revolut/merchant/1.0"
+	"github.com/rysavyvladan/go-revolut/money"
+)
+
+const (
+	// MerchantApiKey is the API key accepted by the merchant endpoints of a new Server
+	MerchantApiKey = "sk_revoluttest"
+	// RefreshToken is the refresh token accepted by a new Server
+	RefreshToken = "oa_revoluttest_refresh"
+
+	accessTokenLifetime = 40 * time.Minute
+	// the time limit of a web-hook delivery, so that a hung receiver does not hold the later events forever
+	webhookTimeout = 5 * time.Second
+)
+
+// Server is a fake Revolut API listening on a local address. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu              sync.Mutex
+	accessTokens    map[string]time.Time
+	refreshTokens   map[string]bool
+	merchantApiKey  string
+	processingDelay time.Duration
+	faults          []*fault
+	requests        []string
+	lastTime        time.Time
+
+	accounts       []*account
+	counterparties []*counterparty
+	transactions   []*transaction
+	rates          map[string]float64
+	drafts         []*paymentDraft
+	webhookUrl     string
+
+	orders           []*order
+	merchantWebhooks []string
+
+	// the web-hook events waiting for deliver, wake signals new ones and done stops deliver
+	queue  []event
+	wake   chan struct{}
+	done   chan struct{}
+	closed bool
+}
+
+// NewServer starts a Server with a GBP, EUR and USD account, each with a balance of 10000.
+func NewServer() *Server {
+	s := &Server{
+		accessTokens:   map[string]time.Time{},
+		refreshTokens:  map[string]bool{RefreshToken: true},
+		merchantApiKey: MerchantApiKey,
+		rates: map[string]float64{
+			"GBP/EUR": 1.11, "EUR/GBP": 0.9,
+			"GBP/USD": 1.29, "USD/GBP": 0.775,
+			"EUR/USD": 1.16, "USD/EUR": 0.862,
+		},
+	}
+	s.AddAccount("Main", "GBP", money.NewFromInt(10000))
+	s.AddAccount("Euro", "EUR", money.NewFromInt(10000))
+	s.AddAccount("Dollar", "USD", money.NewFromInt(10000))
+
+	s.wake = make(chan struct{}, 1)
+	s.done = make(chan struct{})
+	go s.deliver()
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// Close shuts the Server down, pending web-hook events are dropped.
+func (s *Server) Close() {
+	s.Server.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+}
+
+// Environment returns the environment pointing the clients to the Server.
+func (s *Server) Environment() environment.Environment {
+	return environment.Custom(s.URL)
+}
+
+// BusinessClient creates a client of the Business API authorised by the Server,
+// opts are applied after the option selecting the environment.
+func (s *Server) BusinessClient(opts ...business.Option) (*business.Client, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append([]business.Option{business.WithEnvironment(s.Environment())}, opts...)
+
+	return business.NewClient("revoluttest", RefreshToken, privateKey, "revoluttest.local", false, opts...)
+}
+
+// MerchantClient creates a client of the Merchant API authorised by the Server,
+// opts are applied after the option selecting the environment.
+func (s *Server) MerchantClient(opts ...merchant.Option) *merchant.Client {
+	opts = append([]merchant.Option{merchant.WithEnvironment(s.Environment())}, opts...)
+
+	return merchant.NewClient(s.merchantApiKey, opts...)
+}
+
+// ExpireAccessTokens invalidates every issued access token, the next request of a client is rejected with 401.
+func (s *Server) ExpireAccessTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accessTokens = map[string]time.Time{}
+}
+
+// SetProcessingDelay makes pending payments to external counterparties complete automatically after d,
+// by default they stay pending until CompleteTransaction or Advance is called.
+func (s *Server) SetProcessingDelay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.processingDelay = d
+}
+
+// Requests returns the method and path of every request received, e.g. "POST /api/1.0/pay".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+// Fault describes a failure injected into the matching requests.
+type Fault struct {
+	// an optional HTTP method of the matching requests
+	Method string
+	// an optional path pattern of the matching requests in the syntax of path.Match, e.g. /api/1.0/transaction/*
+	Path string
+	// the number of matching requests to fail, 0 fails all of them
+	Times int
+	// the status code of the response, 503 by default
+	StatusCode int
+	// an optional body of the response
+	Body string
+	// optional headers of the response, e.g. Retry-After
+	Header http.Header
+	// an optional delay before the response, e.g. to trigger a client timeout
+	Delay time.Duration
+	// close the connection without a response, the client sees a network error
+	Drop bool
+	// process the request before failing, e.g. to emulate a response lost after the payment was made
+	AfterProcessing bool
+}
+
+type fault struct {
+	Fault
+	hits int
+}
+
+// InjectFault makes the requests matching f fail, faults are matched in the order they were injected.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &fault{Fault: f})
+}
+
+// ClearFaults removes every injected fault.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// matchFault returns the fault for r and counts the hit, nil when no fault matches.
+func (s *Server) matchFault(r *http.Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range s.faults {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if f.Path != "" {
+			if ok, _ := path.Match(f.Path, r.URL.Path); !ok {
+				continue
+			}
+		}
+		if f.Times > 0 && f.hits >= f.Times {
+			continue
+		}
+		f.hits++
+		ff := f.Fault
+		return &ff
+	}
+
+	return nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	s.mu.Unlock()
+
+	f := s.matchFault(r)
+	if f == nil {
+		s.route(w, r)
+		return
+	}
+
+	if f.AfterProcessing {
+		s.route(httptest.NewRecorder(), r)
+	}
+	if f.Delay > 0 {
+		select {
+		case <-time.After(f.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if f.Drop {
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+
+	for k, v := range f.Header {
+		w.Header()[k] = v
+	}
+	statusCode := f.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+	w.WriteHeader(statusCode)
+	fmt.Fprint(w, f.Body)
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	// an escaped / belongs to its segment, e.g. a request ID
+	p := strings.TrimPrefix(r.URL.EscapedPath(), "/api/1.0")
+	seg := strings.Split(strings.Trim(p, "/"), "/")
+	for i := range seg {
+		if v, err := url.PathUnescape(seg[i]); err == nil {
+			seg[i] = v
+		}
+	}
+
+	switch seg[0] {
+	case "auth":
+		s.token(w, r)
+	case "app-confirm":
+		s.appConfirm(w, r)
+	case "orders", "webhooks":
+		if !s.merchantAuthorised(r) {
+			writeMerchantError(w, http.StatusUnauthorized, 1001, "Unauthorized")
+			return
+		}
+		s.routeMerchant(w, r, seg)
+	default:
+		if !s.businessAuthorised(r) {
+			writeBusinessError(w, http.StatusUnauthorized, 401, "The access token is invalid or expired")
+			return
+		}
+		s.routeBusiness(w, r, seg)
+	}
+}
+
+func (s *Server) businessAuthorised(r *http.Request) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exp, ok := s.accessTokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+
+	return ok && time.Now().Before(exp)
+}
+
+func (s *Server) merchantAuthorised(r *http.Request) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return r.Header.Get("Authorization") == "Bearer "+s.merchantApiKey
+}
+
+// token emulates the OAuth token endpoint, the client assertion is required but its signature is not verified.
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeBusinessError(w, http.StatusMethodNotAllowed, 405, "Method not allowed")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, "invalid_request", err.Error())
+		return
+	}
+	if r.PostForm.Get("client_assertion") == "" || r.PostForm.Get("client_id") == "" {
+		writeOAuthError(w, "invalid_client", "client_id and client_assertion are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := map[string]interface{}{
+		"access_token": "oa_revoluttest_" + newId(),
+		"token_type":   "bearer",
+		"expires_in":   int(accessTokenLifetime / time.Second),
+	}
+
+	switch r.PostForm.Get("grant_type") {
+	case "refresh_token":
+		if !s.refreshTokens[r.PostForm.Get("refresh_token")] {
+			writeOAuthError(w, "invalid_grant", "the refresh token is invalid")
+			return
+		}
+	case "authorization_code":
+		if r.PostForm.Get("code") == "" {
+			writeOAuthError(w, "invalid_grant", "the authorisation code is invalid")
+			return
+		}
+		refreshToken := "oa_revoluttest_refresh_" + newId()
+		s.refreshTokens[refreshToken] = true
+		resp["refresh_token"] = refreshToken
+	default:
+		writeOAuthError(w, "unsupported_grant_type", "the grant type is not supported")
+		return
+	}
+
+	s.accessTokens[resp["access_token"].(string)] = time.Now().Add(accessTokenLifetime)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// appConfirm emulates the consent page granting the access right away.
+func (s *Server) appConfirm(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectUri := q.Get("redirect_uri")
+	if redirectUri == "" {
+		http.Error(w, "missing redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	sep := "?"
+	if strings.Contains(redirectUri, "?") {
+		sep = "&"
+	}
+	location := redirectUri + sep + "code=oa_revoluttest_code_" + newId()
+	if state := q.Get("state"); state != "" {
+		location += "&state=" + state
+	}
+
+	http.Redirect(w, r, location, http.StatusFound)
+}
+
+type event struct {
+	url  string
+	body []byte
+}
+
+// notify queues a web-hook event without waiting for its delivery, the caller must hold s.mu.
+func (s *Server) notify(url string, payload interface{}) {
+	if url == "" || s.closed {
+		return
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	s.queue = append(s.queue, event{url: url, body: body})
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver posts the queued web-hook events in order until the Server is closed, failed deliveries are not retried.
+func (s *Server) deliver() {
+	// a delivery in progress is cancelled by Close
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.done
+		cancel()
+	}()
+
+	client := &http.Client{Timeout: webhookTimeout}
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.wake:
+		}
+
+		s.mu.Lock()
+		events := s.queue
+		s.queue = nil
+		s.mu.Unlock()
+
+		for _, e := range events {
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(e.body))
+			if err != nil {
+				continue
+			}
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := client.Do(req)
+			if err != nil {
+				continue
+			}
+			resp.Body.Close()
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if v != nil {
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+func writeBusinessError(w http.ResponseWriter, statusCode, code int, message string) {
+	writeJSON(w, statusCode, map[string]interface{}{"code": code, "message": message})
+}
+
+func writeMerchantError(w http.ResponseWriter, statusCode, code int, message string) {
+	writeJSON(w, statusCode, map[string]interface{}{"code": code, "message": message, "timestamp": millis(time.Now())})
+}
+
+func writeOAuthError(w http.ResponseWriter, code, description string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code, "error_description": description})
+}
+
+// newId returns a random ID in the format of a UUID.
+func newId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b)
+
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
@@ -0,0 +1,232 @@
+package revoluttest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	business "github.com/rysavyvladan/go-revolut/business/1.0"
+	merchant "github.com/rysavyvladan/go-revolut/merchant/1.0"
+	"github.com/rysavyvladan/go-revolut/money"
+)
+
+var fastRetry = business.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+func newBusinessClient(t *testing.T, srv *Server, opts ...business.Option) *business.Client {
+	t.Helper()
+
+	bC, err := srv.BusinessClient(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bC
+}
+
+// count returns the number of requests received by srv with the method and the path prefix.
+func count(srv *Server, method, pathPrefix string) int {
+	n := 0
+	for _, r := range srv.Requests() {
+		if strings.HasPrefix(r, method+" "+pathPrefix) {
+			n++
+		}
+	}
+
+	return n
+}
+
+func TestRetryOnFaults(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv, business.WithRetry(fastRetry))
+
+	srv.InjectFault(Fault{Method: http.MethodGet, Path: "/api/1.0/accounts", Times: 2})
+	accounts, err := bC.Account().List()
+	if err != nil {
+		t.Fatalf("List after 2 faults: %v", err)
+	}
+	if len(accounts) != 3 {
+		t.Errorf("got %d accounts, want 3", len(accounts))
+	}
+	if n := count(srv, http.MethodGet, "/api/1.0/accounts"); n != 3 {
+		t.Errorf("got %d attempts, want 3", n)
+	}
+
+	srv.InjectFault(Fault{Method: http.MethodGet, Path: "/api/1.0/accounts", Drop: true, Times: 1})
+	if _, err := bC.Account().List(); err != nil {
+		t.Errorf("List after a dropped connection: %v", err)
+	}
+
+	srv.InjectFault(Fault{Method: http.MethodGet, Path: "/api/1.0/accounts"})
+	_, err = bC.Account().List()
+	var apiErr *business.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Attempts != 3 {
+		t.Errorf("List with a persistent fault: got %v, want a 503 after 3 attempts", err)
+	}
+}
+
+func TestNoRetry(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	// without a policy nothing is retried
+	bC := newBusinessClient(t, srv)
+	srv.InjectFault(Fault{Method: http.MethodGet, Path: "/api/1.0/accounts", Times: 1})
+	if _, err := bC.Account().List(); err == nil {
+		t.Error("List without a retry policy: got no error")
+	}
+
+	// a POST without a request ID may have been processed, it is not sent again
+	bC = newBusinessClient(t, srv, business.WithRetry(fastRetry))
+	srv.InjectFault(Fault{Method: http.MethodPost, Path: "/api/1.0/counterparty", Times: 1})
+	_, err := bC.Counterparty().AddRevolut(&business.RevolutCounterpartyReq{ProfileType: business.CounterpartyProfileType_PERSONAL, Name: "John Smith", Phone: "+4412345678"})
+	if err == nil {
+		t.Error("AddRevolut with a fault: got no error")
+	}
+	if n := count(srv, http.MethodPost, "/api/1.0/counterparty"); n != 1 {
+		t.Errorf("got %d attempts, want 1", n)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv, business.WithRetry(fastRetry))
+
+	srv.InjectFault(Fault{Method: http.MethodGet, Path: "/api/1.0/accounts", Times: 1, StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"1"}}})
+	start := time.Now()
+	if _, err := bC.Account().List(); err != nil {
+		t.Fatalf("List after a 429: %v", err)
+	}
+	if d := time.Since(start); d < time.Second {
+		t.Errorf("retried after %v, want the Retry-After of 1s", d)
+	}
+}
+
+func TestMerchantRetry(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	mC := srv.MerchantClient(merchant.WithRetry(merchant.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+
+	order, err := mC.Order().Create(&merchant.OrderReq{Amount: 1000, Currency: "GBP"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.InjectFault(Fault{Method: http.MethodGet, Path: "/api/1.0/orders/*", Times: 2, StatusCode: http.StatusBadGateway})
+	got, err := mC.Order().WithId(order.Id)
+	if err != nil {
+		t.Fatalf("WithId after 2 faults: %v", err)
+	}
+	if got.Id != order.Id {
+		t.Errorf("got the order %s, want %s", got.Id, order.Id)
+	}
+}
+
+func TestReauthorisation(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv)
+
+	if _, err := bC.Account().List(); err != nil {
+		t.Fatal(err)
+	}
+	srv.ExpireAccessTokens()
+	if _, err := bC.Account().List(); err != nil {
+		t.Fatalf("List with an expired token: %v", err)
+	}
+	if n := count(srv, http.MethodPost, "/api/1.0/auth/token"); n != 2 {
+		t.Errorf("got %d token requests, want 2", n)
+	}
+}
+
+func TestReauthorisationWithTokenStore(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	store := business.NewMemoryTokenStore()
+	bC := newBusinessClient(t, srv, business.WithTokenStore(store))
+
+	if _, err := bC.Account().List(); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := store.Load()
+	if err != nil || stored == nil || stored.AccessToken == "" {
+		t.Fatalf("the token was not stored: %v %v", stored, err)
+	}
+
+	// the stored token is revoked as well, it must not be sent again
+	srv.ExpireAccessTokens()
+	if _, err := bC.Account().List(); err != nil {
+		t.Fatalf("List with a revoked token in the store: %v", err)
+	}
+	if n := count(srv, http.MethodPost, "/api/1.0/auth/token"); n != 2 {
+		t.Errorf("got %d token requests, want 2", n)
+	}
+	if refreshed, _ := store.Load(); refreshed == nil || refreshed.AccessToken == stored.AccessToken {
+		t.Error("the refreshed token was not stored")
+	}
+
+	// another client sharing the store uses the refreshed token
+	other := newBusinessClient(t, srv, business.WithTokenStore(store))
+	if _, err := other.Account().List(); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(srv, http.MethodPost, "/api/1.0/auth/token"); n != 2 {
+		t.Errorf("got %d token requests, want 2", n)
+	}
+}
+
+func TestRefreshMargin(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	// a margin longer than the lifetime of the tokens refreshes before every request
+	bC := newBusinessClient(t, srv, business.WithRefreshMargin(accessTokenLifetime+time.Minute))
+
+	for i := 0; i < 2; i++ {
+		if _, err := bC.Account().List(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// NewClient requests the first token
+	if n := count(srv, http.MethodPost, "/api/1.0/auth/token"); n != 3 {
+		t.Errorf("got %d token requests, want 3", n)
+	}
+}
+
+func TestHungWebhookReceiver(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	bC := newBusinessClient(t, srv)
+
+	release := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer receiver.Close()
+	defer close(release)
+	srv.SetWebhook(receiver.URL)
+
+	// far more events than a bounded queue would hold, none of the requests may wait for their delivery
+	gbp, cp := payee(t, bC)
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < 300; i++ {
+			if _, err := bC.Payment().Create(&business.PaymentReq{AccountId: gbp, Receiver: business.PaymentReceiver{CounterpartyId: cp.Id, AccountId: cp.Accounts[0].Id}, Amount: money.MustParse("1"), Currency: "GBP"}); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the payments are blocked by the web-hook receiver")
+	}
+}